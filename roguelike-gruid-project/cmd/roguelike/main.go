@@ -5,31 +5,53 @@ package main
 
 import (
 	"context"
-	"math/rand"
+	"fmt"
+	"os"
 	"time"
 
 	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/bestiary"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/game"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/items"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/vaults"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	config.Init()
+	logFile := config.Init()
+	defer logFile.Close()
+	cfg := config.Config
+	if cfg.Backend == "" {
+		cfg.Backend = ui.Backend
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+	if cfg.Backend != ui.Backend {
+		logrus.Fatalf("The %s backend is not available in this build, which uses %s", cfg.Backend, ui.Backend)
+	}
 
 	logrus.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp: true,
 		DisableColors: false,
 	})
 
-	logrus.Infof("Starting roguelike game - Debug mode: %v", config.Config.DebugLogging)
+	logrus.Infof("Starting roguelike game - Log level: %s", cfg.LogLevel)
 
-	seed := time.Now().UnixNano()
-	rand.New(rand.NewSource(seed))
+	loadBestiary()
+	loadItems()
+	loadKeymap()
+	loadVaults()
 
-	gd := gruid.NewGrid(config.DungeonWidth, config.DungeonHeight)
-	m := game.NewModel(gd)
+	m, err := newModel()
+	if err != nil {
+		logrus.Fatal(err)
+	}
 
 	driver := ui.GetDriver()
 	app := gruid.NewApp(gruid.AppConfig{
@@ -43,3 +65,110 @@ func main() {
 		logrus.Fatal(err)
 	}
 }
+
+// newModel returns the main model: either a replay of a recorded run, played
+// with the settings it was recorded with, or a new game recording its input.
+func newModel() (gruid.Model, error) {
+	cfg := config.Config
+	if cfg.Replay != "" {
+		rec, err := game.LoadRecording(cfg.Replay)
+		if err != nil {
+			return nil, err
+		}
+		if err := rec.RestoreConfig(); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Replay, err)
+		}
+		logrus.Infof("Replaying %s (seed %d)", cfg.Replay, rec.Seed)
+		gd := gruid.NewGrid(cfg.UIWidth(), cfg.UIHeight())
+		return game.NewReplayModel(gd, rec, cfg.ReplaySpeed)
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logrus.Infof("Using seed %d", seed)
+	gd := gruid.NewGrid(cfg.UIWidth(), cfg.UIHeight())
+	m := game.NewModel(gd, seed)
+
+	savePath, err := config.SavePath()
+	if err != nil {
+		logrus.WithError(err).Warn("Saving is disabled")
+	}
+	m.SaveTo(savePath)
+
+	recordPath := cfg.Record
+	if recordPath == "" {
+		path, err := config.ReplayPath()
+		if err != nil {
+			logrus.WithError(err).Warn("Input recording is disabled")
+		}
+		recordPath = path
+	}
+	m.RecordTo(recordPath)
+	return m, nil
+}
+
+// loadBestiary loads the user's monster definitions on top of the built-in
+// ones. The built-in bestiary is kept if the user's one is invalid.
+func loadBestiary() {
+	path, err := config.BestiaryPath()
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in bestiary")
+		return
+	}
+	b, err := bestiary.Load(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in bestiary")
+		return
+	}
+	game.SetBestiary(b)
+}
+
+// loadItems loads the user's item definitions on top of the built-in ones.
+// The built-in items are kept if the user's ones are invalid.
+func loadItems() {
+	path, err := config.ItemsPath()
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in items")
+		return
+	}
+	c, err := items.Load(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in items")
+		return
+	}
+	game.SetItemCatalog(c)
+}
+
+// loadKeymap loads the user's key bindings on top of the default ones. The
+// default bindings are kept if the user's ones are invalid.
+func loadKeymap() {
+	path, err := config.KeysPath()
+	if err != nil {
+		logrus.WithError(err).Warn("Using the default key bindings")
+		return
+	}
+	km, err := game.LoadKeymap(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Using the default key bindings")
+		return
+	}
+	game.SetKeymap(km)
+}
+
+// loadVaults loads the user's vault definitions on top of the built-in ones.
+// The built-in vaults are kept if the user's ones are invalid.
+func loadVaults() {
+	path, err := config.VaultsPath()
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in vaults")
+		return
+	}
+	l, err := vaults.Load(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Using the built-in vaults")
+		return
+	}
+	game.SetVaults(l)
+}
@@ -0,0 +1,156 @@
+// Package bestiary defines the monster templates used to spawn monsters. A
+// default bestiary is embedded in the binary, and players can override or
+// extend it with their own data file, so that new creatures need no code.
+package bestiary
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/spawntable"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+//go:embed monsters.json
+var defaultData []byte
+
+// Template describes a kind of monster.
+type Template struct {
+	Name       string `json:"name"`
+	Glyph      string `json:"glyph"` // a single character
+	Color      string `json:"color"` // a color name known by ui.ColorByName
+	Speed      uint64 `json:"speed"` // time between turns, lower is faster
+	HP         int    `json:"hp"`
+	FOVRange   int    `json:"fov_range"`
+	Power      int    `json:"power"`
+	Defense    int    `json:"defense"`
+	Accuracy   int    `json:"accuracy"`
+	Evasion    int    `json:"evasion"`
+	CritChance int    `json:"crit_chance"`
+	AI         string `json:"ai"` // AI behavior, hunter if empty
+	spawntable.Spawn
+}
+
+// Key returns the name of the monster.
+func (t Template) Key() string {
+	return t.Name
+}
+
+// Rune returns the glyph of the monster.
+func (t Template) Rune() rune {
+	r, _ := utf8.DecodeRuneInString(t.Glyph)
+	return r
+}
+
+// FgColor returns the color of the monster glyph.
+func (t Template) FgColor() gruid.Color {
+	c, _ := ui.ColorByName(t.Color)
+	return c
+}
+
+// Behavior returns the AI behavior of the monster.
+func (t Template) Behavior() components.AIBehavior {
+	if t.AI == "" {
+		return components.BehaviorHunter
+	}
+	return components.AIBehavior(t.AI)
+}
+
+// CombatStats returns the fighting abilities of the monster.
+func (t Template) CombatStats() components.CombatStats {
+	return components.CombatStats{
+		Power:      t.Power,
+		Defense:    t.Defense,
+		Accuracy:   t.Accuracy,
+		Evasion:    t.Evasion,
+		CritChance: t.CritChance,
+	}
+}
+
+// validate checks that the template fields have usable values.
+func (t Template) validate() error {
+	switch {
+	case t.Name == "":
+		return errors.New("missing name")
+	case utf8.RuneCountInString(t.Glyph) != 1:
+		return fmt.Errorf("%s: glyph %q is not a single character", t.Name, t.Glyph)
+	case t.Speed == 0:
+		return fmt.Errorf("%s: speed must be positive", t.Name)
+	case t.HP <= 0:
+		return fmt.Errorf("%s: hp must be positive", t.Name)
+	case t.Power <= 0:
+		return fmt.Errorf("%s: power must be positive", t.Name)
+	case t.Defense < 0:
+		return fmt.Errorf("%s: defense must not be negative", t.Name)
+	case t.CritChance < 0 || t.CritChance > 100:
+		return fmt.Errorf("%s: crit_chance must be a percentage", t.Name)
+	case t.FOVRange < 0:
+		return fmt.Errorf("%s: fov_range must not be negative", t.Name)
+	}
+	if err := t.Spawn.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", t.Name, err)
+	}
+	if _, ok := ui.ColorByName(t.Color); !ok {
+		return fmt.Errorf("%s: unknown color %q", t.Name, t.Color)
+	}
+	if !t.Behavior().Valid() {
+		return fmt.Errorf("%s: unknown ai %q", t.Name, t.AI)
+	}
+	return nil
+}
+
+// Bestiary holds the monster templates, in file order.
+type Bestiary struct {
+	spawntable.Table[Template]
+}
+
+// Read reads a list of templates in JSON format.
+func Read(r io.Reader) (*Bestiary, error) {
+	templates, err := readTemplates(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Bestiary{spawntable.Table[Template]{Templates: templates}}, nil
+}
+
+func readTemplates(r io.Reader) ([]Template, error) {
+	var templates []Template
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&templates); err != nil {
+		return nil, fmt.Errorf("decode bestiary: %w", err)
+	}
+	for _, t := range templates {
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("invalid monster: %w", err)
+		}
+	}
+	return templates, nil
+}
+
+// Default returns the bestiary embedded in the binary.
+func Default() *Bestiary {
+	b, err := Read(bytes.NewReader(defaultData))
+	if err != nil {
+		panic(fmt.Sprintf("embedded bestiary: %v", err))
+	}
+	return b
+}
+
+// Load returns the default bestiary overridden by the file at path, if it
+// exists: templates with the name of a default one replace it, and others are
+// added.
+func Load(path string) (*Bestiary, error) {
+	b := Default()
+	if err := b.Override(path, readTemplates); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
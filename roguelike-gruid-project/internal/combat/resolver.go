@@ -0,0 +1,90 @@
+// Package combat resolves attacks between entities from their combat stats.
+// Resolvers only draw randomness from the generator they are given, so that
+// fights are reproducible from the game seed, and can be exercised on their
+// own.
+package combat
+
+import (
+	"math/rand"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+)
+
+// Outcome is the kind of result of an attack.
+type Outcome int
+
+// Attack outcomes.
+const (
+	Miss Outcome = iota
+	Hit
+	Critical
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Miss:
+		return "miss"
+	case Hit:
+		return "hit"
+	case Critical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// Result is the result of an attack.
+type Result struct {
+	Outcome Outcome
+	Damage  int // zero on a miss
+}
+
+// Resolver decides the result of an attack.
+type Resolver interface {
+	Resolve(rng *rand.Rand, attacker, defender components.CombatStats) Result
+}
+
+// StandardResolver is the default resolver. An attack hits with a chance of
+// BaseHitChance plus the attacker accuracy minus the defender evasion, kept
+// within [MinHitChance, MaxHitChance]. A hit deals from 1 to the attacker
+// power, reduced by the defender defense but at least 1. A critical hit deals
+// CritMultiplier times the rolled damage, ignoring defense.
+type StandardResolver struct {
+	BaseHitChance  int
+	MinHitChance   int
+	MaxHitChance   int
+	CritMultiplier int
+}
+
+// DefaultResolver returns the resolver used unless configured otherwise.
+func DefaultResolver() StandardResolver {
+	return StandardResolver{
+		BaseHitChance:  75,
+		MinHitChance:   5,
+		MaxHitChance:   95,
+		CritMultiplier: 2,
+	}
+}
+
+// HitChance returns the chance in percent that the attacker hits the
+// defender.
+func (r StandardResolver) HitChance(attacker, defender components.CombatStats) int {
+	chance := r.BaseHitChance + attacker.Accuracy - defender.Evasion
+	return max(r.MinHitChance, min(r.MaxHitChance, chance))
+}
+
+// Resolve implements Resolver.Resolve.
+func (r StandardResolver) Resolve(rng *rand.Rand, attacker, defender components.CombatStats) Result {
+	if rng.Intn(100) >= r.HitChance(attacker, defender) {
+		return Result{Outcome: Miss}
+	}
+
+	damage := 1
+	if attacker.Power > 1 {
+		damage += rng.Intn(attacker.Power)
+	}
+
+	if rng.Intn(100) < attacker.CritChance {
+		return Result{Outcome: Critical, Damage: damage * r.CritMultiplier}
+	}
+	return Result{Outcome: Hit, Damage: max(1, damage-defender.Defense)}
+}
@@ -0,0 +1,121 @@
+package combat
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+)
+
+// certainResolver hits with a chance of the attacker accuracy minus the
+// defender evasion, so that attacks surely hit or miss at 100 and 0.
+var certainResolver = StandardResolver{
+	BaseHitChance:  0,
+	MinHitChance:   0,
+	MaxHitChance:   100,
+	CritMultiplier: 2,
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name               string
+		attacker, defender components.CombatStats
+		outcome            Outcome
+		minDamage          int
+		maxDamage          int
+	}{
+		{
+			name:     "miss",
+			attacker: components.CombatStats{Power: 5, Accuracy: 0},
+			outcome:  Miss,
+		},
+		{
+			name:      "hit",
+			attacker:  components.CombatStats{Power: 5, Accuracy: 100},
+			defender:  components.CombatStats{Defense: 1},
+			outcome:   Hit,
+			minDamage: 1,
+			maxDamage: 4,
+		},
+		{
+			name:      "minimum damage",
+			attacker:  components.CombatStats{Power: 3, Accuracy: 100},
+			defender:  components.CombatStats{Defense: 10},
+			outcome:   Hit,
+			minDamage: 1,
+			maxDamage: 1,
+		},
+		{
+			name:      "critical ignores defense",
+			attacker:  components.CombatStats{Power: 3, Accuracy: 100, CritChance: 100},
+			defender:  components.CombatStats{Defense: 10},
+			outcome:   Critical,
+			minDamage: 2,
+			maxDamage: 6,
+		},
+		{
+			name:     "evaded",
+			attacker: components.CombatStats{Power: 5, Accuracy: 100, CritChance: 100},
+			defender: components.CombatStats{Evasion: 100},
+			outcome:  Miss,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			for range 100 {
+				res := certainResolver.Resolve(rng, tt.attacker, tt.defender)
+				if res.Outcome != tt.outcome {
+					t.Fatalf("outcome = %s, want %s", res.Outcome, tt.outcome)
+				}
+				if res.Damage < tt.minDamage || res.Damage > tt.maxDamage {
+					t.Fatalf("damage = %d, want between %d and %d", res.Damage, tt.minDamage, tt.maxDamage)
+				}
+			}
+		})
+	}
+}
+
+func TestHitChance(t *testing.T) {
+	r := DefaultResolver()
+	tests := []struct {
+		accuracy, evasion int
+		want              int
+	}{
+		{0, 0, 75},
+		{10, 5, 80},
+		{100, 0, 95},
+		{0, 100, 5},
+	}
+	for _, tt := range tests {
+		got := r.HitChance(components.CombatStats{Accuracy: tt.accuracy}, components.CombatStats{Evasion: tt.evasion})
+		if got != tt.want {
+			t.Errorf("HitChance(accuracy %d, evasion %d) = %d, want %d", tt.accuracy, tt.evasion, got, tt.want)
+		}
+	}
+}
+
+func TestResolveIsDeterministic(t *testing.T) {
+	attacker := components.CombatStats{Power: 6, Accuracy: 5, CritChance: 20}
+	defender := components.CombatStats{Defense: 1, Evasion: 10}
+	fight := func(seed int64) []Result {
+		rng := rand.New(rand.NewSource(seed))
+		results := make([]Result, 50)
+		for i := range results {
+			results[i] = DefaultResolver().Resolve(rng, attacker, defender)
+		}
+		return results
+	}
+
+	a, b := fight(42), fight(42)
+	outcomes := map[Outcome]bool{}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("attack %d: %+v, then %+v with the same seed", i, a[i], b[i])
+		}
+		outcomes[a[i].Outcome] = true
+	}
+	if len(outcomes) != 3 {
+		t.Errorf("outcomes %v, want misses, hits and critical hits", outcomes)
+	}
+}
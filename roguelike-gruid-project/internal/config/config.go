@@ -1,39 +1,255 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 )
 
-// GameConfig holds the configuration for the game
+// AutoGenerator is the level generator setting letting the depth choose the
+// generator of each level.
+const AutoGenerator = "auto"
+
+// Generators are the names of the level generators, besides AutoGenerator.
+var Generators = []string{"rooms", "bsp", "caves", "hybrid"}
+
+// Backends are the names of the supported display backends. Only the one
+// selected by build tags is available in a given binary.
+var Backends = []string{"tcell", "sdl"}
+
+// Rules holds the settings shaping the game itself: the map size, the level
+// generation and what the player can see. Saves and replays keep the rules of
+// their run, which cannot go on the same way with other ones.
+type Rules struct {
+	DungeonWidth       int    `json:"dungeon_width"`
+	DungeonHeight      int    `json:"dungeon_height"`
+	MaxRooms           int    `json:"max_rooms"`
+	RoomMinSize        int    `json:"room_min_size"`
+	RoomMaxSize        int    `json:"room_max_size"`
+	MaxMonstersPerRoom int    `json:"max_monsters_per_room"` // excluding the first room, and more deeper
+	MaxItemsPerRoom    int    `json:"max_items_per_room"`
+	MaxVaults          int    `json:"max_vaults"` // prefab vaults per level, if there is room
+	FovRadius          int    `json:"fov_radius"` // how far the player can see
+	Generator          string `json:"generator"`  // level generator, or AutoGenerator
+}
+
+// GameConfig holds the configuration for the game. The settings with a JSON
+// name can be set in the configuration file, and all can be overridden by
+// command-line flags.
 type GameConfig struct {
-	DebugLogging bool
+	Rules
+
+	Backend  string `json:"backend"`   // display backend, empty for the one built in
+	LogLevel string `json:"log_level"` // logrus level name
+	LogFile  string `json:"log_file"`  // log file path, empty for standard output
+
+	DebugLogging bool    `json:"-"` // shorthand for the debug log level
+	PrintConfig  bool    `json:"-"` // print the effective configuration and exit
+	Seed         int64   `json:"-"` // 0 means a random seed
+	Record       string  `json:"-"` // replay file to record input to, empty for the default
+	Replay       string  `json:"-"` // replay file to play back instead of playing
+	ReplaySpeed  float64 `json:"-"` // playback speed multiplier
+}
+
+// Default returns the default configuration.
+func Default() *GameConfig {
+	return &GameConfig{
+		Rules: Rules{
+			DungeonWidth:       80,
+			DungeonHeight:      24,
+			MaxRooms:           10,
+			RoomMinSize:        6,
+			RoomMaxSize:        10,
+			MaxMonstersPerRoom: 2,
+			MaxItemsPerRoom:    1,
+			MaxVaults:          1,
+			FovRadius:          10,
+			Generator:          AutoGenerator,
+		},
+		LogLevel:    "info",
+		ReplaySpeed: 1,
+	}
 }
 
-// ParseFlags parses command-line flags and returns a GameConfig
-func ParseFlags() *GameConfig {
-	config := &GameConfig{}
-	flag.BoolVar(&config.DebugLogging, "debug", false, "Enable debug logging")
-	flag.BoolVar(&config.DebugLogging, "d", false, "Enable debug logging (shorthand)")
-	flag.Parse()
+// Read reads configuration settings in JSON format on top of the defaults.
+func Read(r io.Reader) (*GameConfig, error) {
+	config := Default()
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(config); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	return config, nil
+}
+
+// Load returns the configuration in the file at path, or the default one if
+// the file does not exist.
+func Load(path string) (*GameConfig, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Write writes the configuration file settings to w in JSON format.
+func (c *GameConfig) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// Validate checks that the settings have usable values, reporting every
+// problem found.
+func (c *GameConfig) Validate() error {
+	var errs []error
+	check := func(name string, v, min, max int) {
+		if v < min || v > max {
+			errs = append(errs, fmt.Errorf("%s is %d, expected between %d and %d", name, v, min, max))
+		}
+	}
+	check("dungeon_width", c.DungeonWidth, 40, 250)
+	check("dungeon_height", c.DungeonHeight, 15, 100)
+	check("max_rooms", c.MaxRooms, 1, 100)
+	// Rooms need a floor tile inside their walls, and room to spare on the map
+	check("room_min_size", c.RoomMinSize, 3, c.RoomMaxSize)
+	check("room_max_size", c.RoomMaxSize, c.RoomMinSize, min(c.DungeonWidth, c.DungeonHeight)-2)
+	check("max_monsters_per_room", c.MaxMonstersPerRoom, 0, 20)
+	check("max_items_per_room", c.MaxItemsPerRoom, 0, 20)
+	check("max_vaults", c.MaxVaults, 0, 10)
+	check("fov_radius", c.FovRadius, 1, 50)
+	if c.Generator != AutoGenerator && !slices.Contains(Generators, c.Generator) {
+		errs = append(errs, fmt.Errorf("generator is %q, expected %q or one of %q", c.Generator, AutoGenerator, Generators))
+	}
+	if c.Backend != "" && !slices.Contains(Backends, c.Backend) {
+		errs = append(errs, fmt.Errorf("backend is %q, expected one of %q", c.Backend, Backends))
+	}
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("log_level: %w", err))
+	}
+	if c.ReplaySpeed <= 0 {
+		errs = append(errs, fmt.Errorf("replay speed is %g, expected a positive value", c.ReplaySpeed))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// UIWidth returns the width of the screen grid.
+func (c *GameConfig) UIWidth() int {
+	return c.DungeonWidth
+}
+
+// UIHeight returns the height of the screen grid: the map, with the status
+// bar and the message panel below it.
+func (c *GameConfig) UIHeight() int {
+	return c.DungeonHeight + StatusHeight + MessageLogHeight
+}
+
+// ParseFlags parses the command-line arguments, without the program name, on
+// top of the configuration file, and returns the resulting GameConfig. The
+// defaults are used, with a warning, if the game directory is not available.
+func ParseFlags(args []string) (*GameConfig, error) {
+	config := Default()
+	path, err := ConfigPath()
+	if err != nil {
+		logrus.WithError(err).Warn("Using the default configuration")
+	} else if config, err = Load(path); err != nil {
+		return nil, err
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.BoolVar(&config.DebugLogging, "debug", false, "Enable debug logging")
+	fs.BoolVar(&config.DebugLogging, "d", false, "Enable debug logging (shorthand)")
+	fs.BoolVar(&config.PrintConfig, "print-config", false, "Print the effective configuration and exit")
+	fs.Int64Var(&config.Seed, "seed", 0, "Seed for the run's randomness (0 for a random seed)")
+	fs.StringVar(&config.Record, "record", "", "Record input to the given replay file (default: last run replay in the game dir)")
+	fs.StringVar(&config.Replay, "replay", "", "Play back the given replay file")
+	fs.Float64Var(&config.ReplaySpeed, "replay-speed", config.ReplaySpeed, "Replay playback speed multiplier")
+	fs.IntVar(&config.DungeonWidth, "width", config.DungeonWidth, "Map width")
+	fs.IntVar(&config.DungeonHeight, "height", config.DungeonHeight, "Map height")
+	fs.IntVar(&config.MaxRooms, "max-rooms", config.MaxRooms, "Number of room placement attempts per level")
+	fs.IntVar(&config.RoomMinSize, "room-min-size", config.RoomMinSize, "Minimum room size")
+	fs.IntVar(&config.RoomMaxSize, "room-max-size", config.RoomMaxSize, "Maximum room size")
+	fs.IntVar(&config.MaxMonstersPerRoom, "max-monsters", config.MaxMonstersPerRoom, "Maximum number of monsters per room")
+	fs.IntVar(&config.MaxItemsPerRoom, "max-items", config.MaxItemsPerRoom, "Maximum number of items per room")
+	fs.IntVar(&config.MaxVaults, "max-vaults", config.MaxVaults, "Maximum number of prefab vaults per level")
+	fs.IntVar(&config.FovRadius, "fov-radius", config.FovRadius, "How far the player can see")
+	fs.StringVar(&config.Generator, "generator", config.Generator, fmt.Sprintf("Level generator, one of %q, or %q to choose by depth", Generators, AutoGenerator))
+	fs.StringVar(&config.Backend, "backend", config.Backend, fmt.Sprintf("Display backend, one of %q (default: the one built in)", Backends))
+	fs.StringVar(&config.LogLevel, "log-level", config.LogLevel, "Log level (trace, debug, info, warn, error)")
+	fs.StringVar(&config.LogFile, "log-file", config.LogFile, "Log file (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
 	if config.DebugLogging {
-		logrus.SetLevel(logrus.DebugLevel)
-		logrus.SetOutput(os.Stdout)
-		logrus.Debug("Debug logging enabled")
-	} else {
-		logrus.SetLevel(logrus.InfoLevel)
-		logrus.SetOutput(os.Stdout)
+		config.LogLevel = "debug"
 	}
 
-	return config
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
-// Global configuration instance
-var Config *GameConfig
+// setupLogging sets the log level and output. It returns the log file, to be
+// closed when the game exits.
+func (c *GameConfig) setupLogging() (io.Closer, error) {
+	level, err := logrus.ParseLevel(c.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	logrus.SetLevel(level)
+	logrus.SetOutput(os.Stdout)
+	var out io.Closer = nopCloser{}
+	if c.LogFile != "" {
+		f, err := os.OpenFile(c.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+		logrus.SetOutput(f)
+		out = f
+	}
+	logrus.Debugf("Log level set to %s", level)
+	return out, nil
+}
 
-// Init initializes the configuration
-func Init() {
-	Config = ParseFlags()
+// nopCloser is the io.Closer of logs written to the standard output.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Global configuration instance, the default one until Init is called
+var Config = Default()
+
+// Init initializes the configuration from the configuration file and the
+// command line. It exits on invalid settings. It returns the log output, to
+// be closed when the game exits.
+func Init() io.Closer {
+	config, err := ParseFlags(os.Args[1:])
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	logFile, err := config.setupLogging()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	Config = config
+	return logFile
 }
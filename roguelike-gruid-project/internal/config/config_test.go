@@ -0,0 +1,25 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseFlagsWithoutConfigDir(t *testing.T) {
+	// os.UserConfigDir fails without any of these
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "")
+	t.Setenv("AppData", "")
+	if _, err := os.UserConfigDir(); err == nil {
+		t.Skip("the user config dir is still available")
+	}
+
+	config, err := ParseFlags(nil)
+	if err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if want := Default(); !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %+v, want the defaults %+v", config, want)
+	}
+}
@@ -1,8 +1,8 @@
 package config
 
-// Game settings & map generation constants
+// Screen layout constants. The map viewport sits at the top of the grid, with
+// the status bar and the message panel below it.
 const (
-	DungeonWidth  = 80
-	DungeonHeight = 24
-	FovRadius     = 10 // How far the player can see
+	StatusHeight     = 2 // Player status line and visible monsters line
+	MessageLogHeight = 4 // Number of recent messages shown under the map
 )
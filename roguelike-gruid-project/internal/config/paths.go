@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appDirName is the name of the directory holding the game files in the user
+// config directory.
+const appDirName = "roguelike-gruid"
+
+// File names in the game directory.
+const (
+	SaveFileName     = "save.gob.gz"
+	ReplayFileName   = "last-run.replay"
+	BestiaryFileName = "monsters.json"
+	ItemsFileName    = "items.json"
+	KeysFileName     = "keys.json"
+	VaultsFileName   = "vaults.txt"
+	ConfigFileName   = "config.json"
+)
+
+// Dir returns the directory holding the user's game files, creating it if
+// needed.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("user config dir: %w", err)
+	}
+	dir := filepath.Join(base, appDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create game dir: %w", err)
+	}
+	return dir, nil
+}
+
+// SavePath returns the path of the save file.
+func SavePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SaveFileName), nil
+}
+
+// ReplayPath returns the path of the default replay file, holding the input
+// of the last run.
+func ReplayPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ReplayFileName), nil
+}
+
+// BestiaryPath returns the path of the user's monster definitions, which
+// override the built-in ones.
+func BestiaryPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, BestiaryFileName), nil
+}
+
+// ItemsPath returns the path of the user's item definitions, which override
+// the built-in ones.
+func ItemsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ItemsFileName), nil
+}
+
+// KeysPath returns the path of the user's key bindings, which override the
+// default ones.
+func KeysPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, KeysFileName), nil
+}
+
+// VaultsPath returns the path of the user's vault definitions, which override
+// the built-in ones.
+func VaultsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, VaultsFileName), nil
+}
+
+// ConfigPath returns the path of the user's game configuration.
+func ConfigPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ConfigFileName), nil
+}
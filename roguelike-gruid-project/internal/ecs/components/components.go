@@ -1,6 +1,7 @@
 package components
 
 import (
+	"encoding/gob"
 	"reflect"
 
 	"codeberg.org/anaseto/gruid"
@@ -11,11 +12,20 @@ type ComponentType string
 
 // Component type constants
 const (
+	CAIMemory       ComponentType = "AIMemory"
+	CAIState        ComponentType = "AIState"
 	CAITag          ComponentType = "AITag"
 	CBlocksMovement ComponentType = "BlocksMovement"
+	CCombatStats    ComponentType = "CombatStats"
+	CConfused       ComponentType = "Confused"
+	CConsumable     ComponentType = "Consumable"
+	CEquipment      ComponentType = "Equipment"
+	CEquippable     ComponentType = "Equippable"
 	CCorpseTag      ComponentType = "CorpseTag"
 	CFOV            ComponentType = "FOV"
 	CHealth         ComponentType = "Health"
+	CInventory      ComponentType = "Inventory"
+	CItem           ComponentType = "Item"
 	CName           ComponentType = "Name"
 	CPlayerTag      ComponentType = "PlayerTag"
 	CPosition       ComponentType = "Position"
@@ -24,18 +34,35 @@ const (
 )
 
 var TypeToComponent = map[ComponentType]reflect.Type{
+	CAIMemory:       reflect.TypeOf(AIMemory{}),
+	CAIState:        reflect.TypeOf(AIState(0)),
 	CAITag:          reflect.TypeOf(AITag{}),
 	CBlocksMovement: reflect.TypeOf(BlocksMovement{}),
+	CCombatStats:    reflect.TypeOf(CombatStats{}),
+	CConfused:       reflect.TypeOf(Confused{}),
+	CConsumable:     reflect.TypeOf(Consumable{}),
+	CEquipment:      reflect.TypeOf(Equipment{}),
+	CEquippable:     reflect.TypeOf(Equippable{}),
 	CCorpseTag:      reflect.TypeOf(CorpseTag{}),
 	CFOV:            reflect.TypeOf((*FOV)(nil)),
 	CHealth:         reflect.TypeOf(Health{}),
-	CName:           reflect.TypeOf(""),
+	CInventory:      reflect.TypeOf(Inventory{}),
+	CItem:           reflect.TypeOf(Item{}),
+	CName:           reflect.TypeOf(Name{}),
 	CPlayerTag:      reflect.TypeOf(PlayerTag{}),
 	CPosition:       reflect.TypeOf(gruid.Point{}),
 	CRenderable:     reflect.TypeOf(Renderable{}),
 	CTurnActor:      reflect.TypeOf(TurnActor{}),
 }
 
+// Register every component type with gob, so that components stored behind
+// interface values can be saved and restored.
+func init() {
+	for _, t := range TypeToComponent {
+		gob.Register(reflect.Zero(t).Interface())
+	}
+}
+
 // GetGoType returns the corresponding Go type for a ComponentType
 func GetGoType(compType ComponentType) (reflect.Type, bool) {
 	t, ok := TypeToComponent[compType]
@@ -64,6 +91,195 @@ type Health struct {
 	MaxHP     int
 }
 
+// AIMemory component holds what an AI-controlled entity remembers of its
+// target
+type AIMemory struct {
+	LastKnownTarget gruid.Point // where the target was last seen
+	Tracking        bool        // whether the entity is heading for LastKnownTarget
+	PatrolGoal      gruid.Point // where the entity is wandering to
+	Patrolling      bool        // whether the entity is heading for PatrolGoal
+}
+
+// AIState component holds the current behavior state of an AI-controlled
+// entity
+type AIState uint8
+
+// AI states.
+const (
+	AIAsleep AIState = iota
+	AIWandering
+	AIHunting
+	AIFleeing
+)
+
+func (s AIState) String() string {
+	switch s {
+	case AIAsleep:
+		return "asleep"
+	case AIWandering:
+		return "wandering"
+	case AIHunting:
+		return "hunting"
+	case AIFleeing:
+		return "fleeing"
+	}
+	return "unknown"
+}
+
+// CombatStats component holds an entity's fighting abilities
+type CombatStats struct {
+	Power      int // maximum damage dealt by a normal hit
+	Defense    int // damage absorbed from each normal hit
+	Accuracy   int // bonus to the chance of hitting, in percent
+	Evasion    int // malus to the chance of being hit, in percent
+	CritChance int // chance of a critical hit, in percent
+}
+
+// Item component marks an entity as an item that can be picked up
+type Item struct {
+	Template string // name of the item template
+}
+
+// Consumable component marks an item used up for an effect
+type Consumable struct {
+	Effect ItemEffect
+	Power  int // strength of the effect, such as the health restored
+}
+
+// ItemEffect is the effect of using a consumable item.
+type ItemEffect string
+
+// Effects available to item templates.
+const (
+	EffectHeal      ItemEffect = "heal"      // restores health
+	EffectTeleport  ItemEffect = "teleport"  // moves the user to a random place
+	EffectConfuse   ItemEffect = "confuse"   // makes a target enemy stumble around
+	EffectLightning ItemEffect = "lightning" // strikes the nearest enemy
+)
+
+// Targeted reports whether the effect applies to a target chosen by the user.
+func (e ItemEffect) Targeted() bool {
+	return e == EffectConfuse
+}
+
+// Valid reports whether e is a known effect.
+func (e ItemEffect) Valid() bool {
+	switch e {
+	case EffectHeal, EffectTeleport, EffectConfuse, EffectLightning:
+		return true
+	}
+	return false
+}
+
+// Equippable component marks an item that can be worn or wielded
+type Equippable struct {
+	Slot  EquipSlot
+	Bonus CombatStats // combat stats added while equipped
+}
+
+// EquipSlot is the body slot an equippable item occupies.
+type EquipSlot string
+
+// Equipment slots, in display order.
+const (
+	SlotWeapon EquipSlot = "weapon"
+	SlotArmor  EquipSlot = "armor"
+	SlotRing   EquipSlot = "ring"
+	SlotAmulet EquipSlot = "amulet"
+)
+
+// EquipSlots lists every equipment slot, in display order.
+var EquipSlots = []EquipSlot{SlotWeapon, SlotArmor, SlotRing, SlotAmulet}
+
+// Valid reports whether s is a known slot.
+func (s EquipSlot) Valid() bool {
+	switch s {
+	case SlotWeapon, SlotArmor, SlotRing, SlotAmulet:
+		return true
+	}
+	return false
+}
+
+// Equipment component holds the items worn or wielded by an entity. Equipped
+// items stay in the entity's inventory.
+type Equipment struct {
+	Weapon int // entity IDs of the equipped items, 0 for an empty slot
+	Armor  int
+	Ring   int
+	Amulet int
+}
+
+// Item returns the entity ID of the item in a slot, 0 if the slot is empty.
+func (e Equipment) Item(slot EquipSlot) int {
+	switch slot {
+	case SlotWeapon:
+		return e.Weapon
+	case SlotArmor:
+		return e.Armor
+	case SlotRing:
+		return e.Ring
+	case SlotAmulet:
+		return e.Amulet
+	}
+	return 0
+}
+
+// SetItem puts the item with the given entity ID in a slot, 0 to empty it.
+func (e *Equipment) SetItem(slot EquipSlot, id int) {
+	switch slot {
+	case SlotWeapon:
+		e.Weapon = id
+	case SlotArmor:
+		e.Armor = id
+	case SlotRing:
+		e.Ring = id
+	case SlotAmulet:
+		e.Amulet = id
+	}
+}
+
+// SlotOf returns the slot holding the item with the given entity ID.
+func (e Equipment) SlotOf(id int) (EquipSlot, bool) {
+	for _, slot := range EquipSlots {
+		if e.Item(slot) == id {
+			return slot, true
+		}
+	}
+	return "", false
+}
+
+// Confused component makes an AI-controlled entity move at random
+type Confused struct {
+	Turns int // turns left before the entity recovers
+}
+
+// Inventory component holds the items carried by an entity
+type Inventory struct {
+	Items    []int // entity IDs of the carried items, in pickup order
+	Capacity int   // maximum number of carried items
+}
+
+func NewInventory(capacity int) Inventory {
+	return Inventory{Capacity: capacity}
+}
+
+// Full reports whether the inventory cannot hold any more items.
+func (inv Inventory) Full() bool {
+	return len(inv.Items) >= inv.Capacity
+}
+
+// Add returns the sum of two sets of combat stats, such as base stats and
+// equipment bonuses.
+func (s CombatStats) Add(other CombatStats) CombatStats {
+	return CombatStats{
+		Power:      s.Power + other.Power,
+		Defense:    s.Defense + other.Defense,
+		Accuracy:   s.Accuracy + other.Accuracy,
+		Evasion:    s.Evasion + other.Evasion,
+		CritChance: s.CritChance + other.CritChance,
+	}
+}
+
 func NewHealth(maxHP int) Health {
 	return Health{
 		CurrentHP: maxHP,
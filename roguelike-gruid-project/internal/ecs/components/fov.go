@@ -1,6 +1,9 @@
 package components
 
 import (
+	"bytes"
+	"encoding/gob"
+
 	"codeberg.org/anaseto/gruid"
 	"codeberg.org/anaseto/gruid/rl"
 )
@@ -98,3 +101,27 @@ func (f *FOV) GetVisiblePoints(mapWidth int) []gruid.Point {
 
 	return points
 }
+
+// fovData is the serialized form of a FOV component.
+type fovData struct {
+	Range   int
+	Visible []uint64
+	FOV     *rl.FOV
+}
+
+// GobEncode implements gob.GobEncoder, including the internal FOV calculator.
+func (f *FOV) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(fovData{Range: f.Range, Visible: f.Visible, FOV: f.fov})
+	return buf.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (f *FOV) GobDecode(data []byte) error {
+	var fd fovData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fd); err != nil {
+		return err
+	}
+	f.Range, f.Visible, f.fov = fd.Range, fd.Visible, fd.FOV
+	return nil
+}
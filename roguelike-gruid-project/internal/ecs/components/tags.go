@@ -7,7 +7,29 @@ type BlocksMovement struct{}
 type PlayerTag struct{}
 
 // AITag component marks an entity as having AI control
-type AITag struct{}
+type AITag struct {
+	Behavior AIBehavior
+}
+
+// AIBehavior is the general behavior of an AI-controlled entity.
+type AIBehavior string
+
+// AI behaviors available to monster templates.
+const (
+	BehaviorHunter   AIBehavior = "hunter"   // chases the player on sight
+	BehaviorWanderer AIBehavior = "wanderer" // roams around the level
+	BehaviorSleeper  AIBehavior = "sleeper"  // starts asleep
+	BehaviorCoward   AIBehavior = "coward"   // keeps away from the player
+)
+
+// Valid reports whether b is a known behavior.
+func (b AIBehavior) Valid() bool {
+	switch b {
+	case BehaviorHunter, BehaviorWanderer, BehaviorSleeper, BehaviorCoward:
+		return true
+	}
+	return false
+}
 
 // CorpseTag component marks an entity as a corpse
 type CorpseTag struct{}
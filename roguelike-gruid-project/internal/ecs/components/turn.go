@@ -1,7 +1,9 @@
 package components
 
 import (
+	"bytes"
 	"container/list"
+	"encoding/gob"
 )
 
 // TurnActor represents an entity that takes turns in the game
@@ -53,7 +55,49 @@ func (ta *TurnActor) PeekNextAction() any {
 	return element.Value
 }
 
+// ClearActions removes every queued action
+func (ta *TurnActor) ClearActions() {
+	ta.actions.Init()
+}
+
 // IsAlive returns whether the actor is alive
 func (ta *TurnActor) IsAlive() bool {
 	return ta.Alive
 }
+
+// turnActorData is the serialized form of a TurnActor. Queued actions are
+// stored as interface values, so their concrete types must be registered with
+// gob by the package defining them.
+type turnActorData struct {
+	Speed        uint64
+	Alive        bool
+	NextTurnTime uint64
+	Actions      []any
+}
+
+// GobEncode implements gob.GobEncoder, including the queued actions.
+func (ta TurnActor) GobEncode() ([]byte, error) {
+	data := turnActorData{Speed: ta.Speed, Alive: ta.Alive, NextTurnTime: ta.NextTurnTime}
+	if ta.actions != nil {
+		for e := ta.actions.Front(); e != nil; e = e.Next() {
+			data.Actions = append(data.Actions, e.Value)
+		}
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(data)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (ta *TurnActor) GobDecode(b []byte) error {
+	var data turnActorData
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+	ta.Speed, ta.Alive, ta.NextTurnTime = data.Speed, data.Alive, data.NextTurnTime
+	ta.actions = list.New()
+	for _, action := range data.Actions {
+		ta.actions.PushBack(action)
+	}
+	return nil
+}
@@ -50,6 +50,36 @@ func (ecs *ECS) RemoveEntity(id EntityID) {
 	}
 }
 
+// EntityComponents returns all the components of an entity, by type.
+func (ecs *ECS) EntityComponents(id EntityID) map[components.ComponentType]any {
+	ecs.mu.RLock()
+	defer ecs.mu.RUnlock()
+	comps := make(map[components.ComponentType]any)
+	for compType, byEntity := range ecs.components {
+		if c, ok := byEntity[id]; ok {
+			comps[compType] = c
+		}
+	}
+	return comps
+}
+
+// RestoreEntity adds back an entity with its former ID and the components
+// returned by EntityComponents.
+func (ecs *ECS) RestoreEntity(id EntityID, comps map[components.ComponentType]any) {
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+	ecs.entities[id] = struct{}{}
+	if id >= ecs.nextEntityID {
+		ecs.nextEntityID = id + 1
+	}
+	for compType, c := range comps {
+		if ecs.components[compType] == nil {
+			ecs.components[compType] = make(map[EntityID]any)
+		}
+		ecs.components[compType][id] = c
+	}
+}
+
 // EntityExists checks if an entity exists.
 func (ecs *ECS) EntityExists(id EntityID) bool {
 	ecs.mu.RLock()
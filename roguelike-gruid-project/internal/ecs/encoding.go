@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+)
+
+// ecsData is the serialized form of the ECS. Component values are stored as
+// interfaces: their types are registered with gob by the components package.
+type ecsData struct {
+	NextEntityID EntityID
+	Entities     []EntityID
+	Components   map[components.ComponentType]map[EntityID]any
+}
+
+// GobEncode implements gob.GobEncoder.
+func (ecs *ECS) GobEncode() ([]byte, error) {
+	ecs.mu.RLock()
+	defer ecs.mu.RUnlock()
+
+	data := ecsData{
+		NextEntityID: ecs.nextEntityID,
+		Entities:     make([]EntityID, 0, len(ecs.entities)),
+		Components:   ecs.components,
+	}
+	for id := range ecs.entities {
+		data.Entities = append(data.Entities, id)
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(data)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (ecs *ECS) GobDecode(b []byte) error {
+	var data ecsData
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+
+	ecs.nextEntityID = data.NextEntityID
+	ecs.entities = make(map[EntityID]struct{}, len(data.Entities))
+	for _, id := range data.Entities {
+		ecs.entities[id] = struct{}{}
+	}
+	ecs.components = data.Components
+	if ecs.components == nil {
+		ecs.components = make(map[components.ComponentType]map[EntityID]any)
+	}
+	return nil
+}
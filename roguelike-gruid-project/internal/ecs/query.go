@@ -1,6 +1,8 @@
 package ecs
 
 import (
+	"slices"
+
 	"codeberg.org/anaseto/gruid"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
 	"github.com/sirupsen/logrus"
@@ -13,6 +15,9 @@ func (ecs *ECS) entityExists(id EntityID) bool {
 }
 
 // EntitiesAt returns a slice of EntityIDs located at the given point.
+//
+// Like the other queries returning several entities, the result is sorted by
+// ID, so that systems iterating over it behave deterministically.
 func (ecs *ECS) EntitiesAt(p gruid.Point) []EntityID {
 	ecs.mu.RLock()
 	defer ecs.mu.RUnlock()
@@ -25,6 +30,7 @@ func (ecs *ECS) EntitiesAt(p gruid.Point) []EntityID {
 			}
 		}
 	}
+	slices.Sort(ids)
 	return ids
 }
 
@@ -51,6 +57,7 @@ func (ecs *ECS) GetAllEntities() []EntityID {
 	for id := range ecs.entities {
 		ids = append(ids, id)
 	}
+	slices.Sort(ids)
 	return ids
 }
 
@@ -66,6 +73,7 @@ func (ecs *ECS) GetEntitiesWithComponent(compType components.ComponentType) []En
 			entities = append(entities, id)
 		}
 	}
+	slices.Sort(entities)
 	return entities
 }
 
@@ -182,6 +190,41 @@ func (ecs *ECS) GetHealth(id EntityID) (components.Health, bool) {
 	return GetComponentTyped[components.Health](ecs, id, components.CHealth)
 }
 
+// GetCombatStats returns the combat stats component for an entity.
+func (ecs *ECS) GetCombatStats(id EntityID) (components.CombatStats, bool) {
+	return GetComponentTyped[components.CombatStats](ecs, id, components.CCombatStats)
+}
+
+// GetItem returns the item component for an entity.
+func (ecs *ECS) GetItem(id EntityID) (components.Item, bool) {
+	return GetComponentTyped[components.Item](ecs, id, components.CItem)
+}
+
+// GetConsumable returns the consumable component for an entity.
+func (ecs *ECS) GetConsumable(id EntityID) (components.Consumable, bool) {
+	return GetComponentTyped[components.Consumable](ecs, id, components.CConsumable)
+}
+
+// GetEquippable returns the equippable component for an entity.
+func (ecs *ECS) GetEquippable(id EntityID) (components.Equippable, bool) {
+	return GetComponentTyped[components.Equippable](ecs, id, components.CEquippable)
+}
+
+// GetEquipment returns the equipment component for an entity.
+func (ecs *ECS) GetEquipment(id EntityID) (components.Equipment, bool) {
+	return GetComponentTyped[components.Equipment](ecs, id, components.CEquipment)
+}
+
+// GetConfused returns the confused component for an entity.
+func (ecs *ECS) GetConfused(id EntityID) (components.Confused, bool) {
+	return GetComponentTyped[components.Confused](ecs, id, components.CConfused)
+}
+
+// GetInventory returns the inventory component for an entity.
+func (ecs *ECS) GetInventory(id EntityID) (components.Inventory, bool) {
+	return GetComponentTyped[components.Inventory](ecs, id, components.CInventory)
+}
+
 // GetFOV returns the FOV component for an entity.
 func (ecs *ECS) GetFOV(id EntityID) (*components.FOV, bool) {
 	return GetComponentTyped[*components.FOV](ecs, id, components.CFOV)
@@ -202,6 +245,16 @@ func (ecs *ECS) GetAITag(id EntityID) (components.AITag, bool) {
 	return GetComponentTyped[components.AITag](ecs, id, components.CAITag)
 }
 
+// GetAIMemory returns the AIMemory component for an entity.
+func (ecs *ECS) GetAIMemory(id EntityID) (components.AIMemory, bool) {
+	return GetComponentTyped[components.AIMemory](ecs, id, components.CAIMemory)
+}
+
+// GetAIState returns the AIState component for an entity.
+func (ecs *ECS) GetAIState(id EntityID) (components.AIState, bool) {
+	return GetComponentTyped[components.AIState](ecs, id, components.CAIState)
+}
+
 // GetCorpseTag returns the CorpseTag component for an entity.
 func (ecs *ECS) GetCorpseTag(id EntityID) (components.CorpseTag, bool) {
 	return GetComponentTyped[components.CorpseTag](ecs, id, components.CCorpseTag)
@@ -2,8 +2,10 @@ package game
 
 import (
 	"fmt"
+	"slices"
 
 	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/combat"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
@@ -39,6 +41,14 @@ func (a MoveAction) Execute(g *Game) (cost uint, err error) {
 		// Bumped into something, action didn't fully succeed in moving
 		return 0, nil // No time cost for a bump
 	}
+	if a.EntityID == g.PlayerID {
+		pos, _ := g.ecs.GetPosition(a.EntityID)
+		g.makeNoise(pos, stepNoise)
+		if itemsHere := g.ecs.GetEntitiesAtWithComponents(pos, components.CItem); len(itemsHere) > 0 {
+			itemName, _ := g.ecs.GetName(itemsHere[0])
+			g.log.AddMessagef(ui.ColorUIText, "You see here a %s.", itemName)
+		}
+	}
 	return 100, nil // Standard move cost
 }
 
@@ -60,9 +70,8 @@ func (a AttackAction) Execute(g *Game) (cost uint, err error) {
 		return 0, fmt.Errorf("target %d has no health", a.TargetID)
 	}
 
-	// --- Basic Damage Calculation ---
-	damage := 1 // Simple fixed damage for now
-	targetHealth.CurrentHP -= damage
+	result := g.resolver.Resolve(g.rand, g.combatStats(a.AttackerID), g.combatStats(a.TargetID))
+	targetHealth.CurrentHP -= result.Damage
 
 	// Determine message color based on who is attacking
 	var msgColor gruid.Color
@@ -73,40 +82,307 @@ func (a AttackAction) Execute(g *Game) (cost uint, err error) {
 	} else {
 		msgColor = ui.ColorNeutralAttack // Define in ui/color.go
 	}
-	g.log.AddMessagef(msgColor, "%s attacks %s for %d damage.", attackerName, targetName, damage)
+	switch result.Outcome {
+	case combat.Miss:
+		g.log.AddMessagef(ui.ColorMiss, "%s attacks %s but misses.", attackerName, targetName)
+	case combat.Critical:
+		g.log.AddMessagef(ui.ColorCriticalHit, "%s critically hits %s for %d damage!", attackerName, targetName, result.Damage)
+	default:
+		g.log.AddMessagef(msgColor, "%s attacks %s for %d damage.", attackerName, targetName, result.Damage)
+	}
 
-	logrus.Infof("%s (%d) attacks %s (%d) for %d damage. %s HP: %d/%d",
+	logrus.Infof("%s (%d) attacks %s (%d): %s for %d damage. %s HP: %d/%d",
 		attackerName, a.AttackerID,
 		targetName, a.TargetID,
-		damage,
+		result.Outcome, result.Damage,
 		targetName, targetHealth.CurrentHP, targetHealth.MaxHP)
 	g.ecs.AddComponent(a.TargetID, components.CHealth, targetHealth)
 
+	// Fights are loud, and wake up the target
+	attackerPos, _ := g.ecs.GetPosition(a.AttackerID)
+	g.makeNoise(attackerPos, attackNoise)
+	g.alertMonster(a.TargetID, attackerPos)
+
 	// Check for death (CurrentHP <= 0) and handle it
 	if targetHealth.IsDead() {
-		g.handleEntityDeath(a.TargetID, targetName)
+		g.handleEntityDeath(a.TargetID, targetName, a.AttackerID)
 	}
 
 	return 100, nil // Standard attack cost
 }
 
+// SetDamageResolver sets the resolver deciding the result of attacks, the
+// default one unless set.
+func (g *Game) SetDamageResolver(r combat.Resolver) {
+	g.resolver = r
+}
+
+// combatStats returns the combat stats of an entity, including the bonuses of
+// its equipment. Entities without them fight with a power of 1.
+func (g *Game) combatStats(id ecs.EntityID) components.CombatStats {
+	stats, ok := g.ecs.GetCombatStats(id)
+	if !ok {
+		stats = components.CombatStats{Power: 1}
+	}
+	equipment, _ := g.ecs.GetEquipment(id)
+	for _, slot := range components.EquipSlots {
+		if item, ok := g.ecs.GetEquippable(ecs.EntityID(equipment.Item(slot))); ok {
+			stats = stats.Add(item.Bonus)
+		}
+	}
+	return stats
+}
+
+// DescendAction takes the player down the stairs they stand on.
+type DescendAction struct {
+	EntityID ecs.EntityID
+}
+
+// Execute performs the descend action.
+func (a DescendAction) Execute(g *Game) (cost uint, err error) {
+	pos, _ := g.ecs.GetPosition(a.EntityID)
+	if a.EntityID != g.PlayerID || g.dungeon.Grid.At(pos) != StairsDownCell {
+		return 0, fmt.Errorf("entity %d is not on stairs down", a.EntityID)
+	}
+	g.changeLevel(g.Depth + 1)
+	g.log.AddMessagef(ui.ColorStairs, "You descend to depth %d.", g.Depth)
+	return 100, nil
+}
+
+// AscendAction takes the player up the stairs they stand on.
+type AscendAction struct {
+	EntityID ecs.EntityID
+}
+
+// Execute performs the ascend action.
+func (a AscendAction) Execute(g *Game) (cost uint, err error) {
+	pos, _ := g.ecs.GetPosition(a.EntityID)
+	if a.EntityID != g.PlayerID || g.dungeon.Grid.At(pos) != StairsUpCell {
+		return 0, fmt.Errorf("entity %d is not on stairs up", a.EntityID)
+	}
+	g.changeLevel(g.Depth - 1)
+	g.log.AddMessagef(ui.ColorStairs, "You climb up to depth %d.", g.Depth)
+	return 100, nil
+}
+
+// PickupAction makes an entity pick up the first item lying where it stands.
+type PickupAction struct {
+	EntityID ecs.EntityID
+}
+
+// Execute performs the pickup action.
+func (a PickupAction) Execute(g *Game) (cost uint, err error) {
+	pos, _ := g.ecs.GetPosition(a.EntityID)
+	itemsHere := g.ecs.GetEntitiesAtWithComponents(pos, components.CItem)
+	if len(itemsHere) == 0 {
+		return 0, fmt.Errorf("no item at %v for entity %d", pos, a.EntityID)
+	}
+	inv, ok := g.ecs.GetInventory(a.EntityID)
+	if !ok {
+		return 0, fmt.Errorf("entity %d has no inventory", a.EntityID)
+	}
+	if inv.Full() {
+		return 0, fmt.Errorf("inventory of entity %d is full", a.EntityID)
+	}
+
+	itemID := itemsHere[0]
+	g.ecs.RemoveComponent(itemID, components.CPosition)
+	inv.Items = append(inv.Items, int(itemID))
+	g.ecs.AddComponent(a.EntityID, components.CInventory, inv)
+
+	if a.EntityID == g.PlayerID {
+		itemName, _ := g.ecs.GetName(itemID)
+		g.log.AddMessagef(ui.ColorItem, "You pick up the %s.", itemName)
+	}
+	return 100, nil
+}
+
+// DropAction makes an entity drop one of its carried items where it stands.
+type DropAction struct {
+	EntityID ecs.EntityID
+	ItemID   ecs.EntityID
+}
+
+// Execute performs the drop action.
+func (a DropAction) Execute(g *Game) (cost uint, err error) {
+	inv, ok := g.ecs.GetInventory(a.EntityID)
+	if !ok {
+		return 0, fmt.Errorf("entity %d has no inventory", a.EntityID)
+	}
+	i := slices.Index(inv.Items, int(a.ItemID))
+	if i < 0 {
+		return 0, fmt.Errorf("entity %d does not carry item %d", a.EntityID, a.ItemID)
+	}
+
+	if equipment, ok := g.ecs.GetEquipment(a.EntityID); ok {
+		if _, worn := equipment.SlotOf(int(a.ItemID)); worn {
+			g.unequip(a.EntityID, a.ItemID)
+		}
+	}
+
+	pos, _ := g.ecs.GetPosition(a.EntityID)
+	inv.Items = slices.Delete(inv.Items, i, i+1)
+	g.ecs.AddComponent(a.EntityID, components.CInventory, inv)
+	g.ecs.AddComponent(a.ItemID, components.CPosition, pos)
+
+	if a.EntityID == g.PlayerID {
+		itemName, _ := g.ecs.GetName(a.ItemID)
+		g.log.AddMessagef(ui.ColorItem, "You drop the %s.", itemName)
+	}
+	return 100, nil
+}
+
+// UseItemAction makes an entity use one of its carried consumable items, which
+// is used up if its effect happens.
+type UseItemAction struct {
+	EntityID ecs.EntityID
+	ItemID   ecs.EntityID
+	Target   gruid.Point // target of targeted effects
+}
+
+// Execute performs the use item action. It takes no time if the effect cannot
+// happen, and the item is then kept.
+func (a UseItemAction) Execute(g *Game) (cost uint, err error) {
+	inv, ok := g.ecs.GetInventory(a.EntityID)
+	if !ok {
+		return 0, fmt.Errorf("entity %d has no inventory", a.EntityID)
+	}
+	i := slices.Index(inv.Items, int(a.ItemID))
+	if i < 0 {
+		return 0, fmt.Errorf("entity %d does not carry item %d", a.EntityID, a.ItemID)
+	}
+	consumable, ok := g.ecs.GetConsumable(a.ItemID)
+	if !ok {
+		return 0, fmt.Errorf("item %d is not consumable", a.ItemID)
+	}
+	effect, ok := itemEffects[consumable.Effect]
+	if !ok {
+		return 0, fmt.Errorf("item %d has unknown effect %q", a.ItemID, consumable.Effect)
+	}
+
+	itemName, _ := g.ecs.GetName(a.ItemID)
+	logrus.Infof("Entity %d uses %s (%d)", a.EntityID, itemName, a.ItemID)
+	if !effect(g, a.EntityID, consumable.Power, a.Target) {
+		return 0, nil
+	}
+
+	// The inventory may have changed, for example if the user died
+	if inv, ok = g.ecs.GetInventory(a.EntityID); ok {
+		inv.Items = slices.DeleteFunc(inv.Items, func(id int) bool { return id == int(a.ItemID) })
+		g.ecs.AddComponent(a.EntityID, components.CInventory, inv)
+	}
+	g.ecs.RemoveEntity(a.ItemID)
+	return 100, nil
+}
+
+// EquipAction makes an entity wear or wield one of its carried items, taking
+// off the item previously in the same slot.
+type EquipAction struct {
+	EntityID ecs.EntityID
+	ItemID   ecs.EntityID
+}
+
+// Execute performs the equip action.
+func (a EquipAction) Execute(g *Game) (cost uint, err error) {
+	inv, ok := g.ecs.GetInventory(a.EntityID)
+	if !ok || !slices.Contains(inv.Items, int(a.ItemID)) {
+		return 0, fmt.Errorf("entity %d does not carry item %d", a.EntityID, a.ItemID)
+	}
+	item, ok := g.ecs.GetEquippable(a.ItemID)
+	if !ok {
+		return 0, fmt.Errorf("item %d is not equippable", a.ItemID)
+	}
+	equipment, ok := g.ecs.GetEquipment(a.EntityID)
+	if !ok {
+		return 0, fmt.Errorf("entity %d cannot equip items", a.EntityID)
+	}
+
+	if old := ecs.EntityID(equipment.Item(item.Slot)); old != 0 && old != a.ItemID {
+		g.unequip(a.EntityID, old)
+		equipment, _ = g.ecs.GetEquipment(a.EntityID)
+	}
+	equipment.SetItem(item.Slot, int(a.ItemID))
+	g.ecs.AddComponent(a.EntityID, components.CEquipment, equipment)
+
+	if a.EntityID == g.PlayerID {
+		itemName, _ := g.ecs.GetName(a.ItemID)
+		verb := "put on"
+		if item.Slot == components.SlotWeapon {
+			verb = "wield"
+		}
+		g.log.AddMessagef(ui.ColorItem, "You %s the %s.", verb, itemName)
+	}
+	return 100, nil
+}
+
+// UnequipAction makes an entity take off one of its equipped items, which
+// stays in its inventory.
+type UnequipAction struct {
+	EntityID ecs.EntityID
+	ItemID   ecs.EntityID
+}
+
+// Execute performs the unequip action.
+func (a UnequipAction) Execute(g *Game) (cost uint, err error) {
+	equipment, ok := g.ecs.GetEquipment(a.EntityID)
+	if !ok {
+		return 0, fmt.Errorf("entity %d cannot equip items", a.EntityID)
+	}
+	if _, worn := equipment.SlotOf(int(a.ItemID)); !worn {
+		return 0, fmt.Errorf("entity %d does not wear item %d", a.EntityID, a.ItemID)
+	}
+	g.unequip(a.EntityID, a.ItemID)
+	return 100, nil
+}
+
+// unequip empties the slot holding an equipped item.
+func (g *Game) unequip(entityID, itemID ecs.EntityID) {
+	equipment, _ := g.ecs.GetEquipment(entityID)
+	slot, ok := equipment.SlotOf(int(itemID))
+	if !ok {
+		return
+	}
+	equipment.SetItem(slot, 0)
+	g.ecs.AddComponent(entityID, components.CEquipment, equipment)
+
+	if entityID == g.PlayerID {
+		itemName, _ := g.ecs.GetName(itemID)
+		verb := "take off"
+		if slot == components.SlotWeapon {
+			verb = "put away"
+		}
+		g.log.AddMessagef(ui.ColorItem, "You %s the %s.", verb, itemName)
+	}
+}
+
 // handleEntityDeath handles an entity's death, either removing it completely
 // or turning it into a corpse (the preferred option)
-func (g *Game) handleEntityDeath(entityID ecs.EntityID, entityName string) {
+func (g *Game) handleEntityDeath(entityID ecs.EntityID, entityName string, killerID ecs.EntityID) {
 	g.log.AddMessagef(ui.ColorDeath, "%s dies!", entityName)
 	logrus.Infof("Entity %s (%d) has died.", entityName, entityID)
 
 	if entityID == g.PlayerID {
-		g.log.AddMessagef(ui.ColorCritical, "You died! Game over!")
+		g.log.AddCriticalf(ui.ColorCritical, "You died! Game over!")
 		logrus.Info("Player has died. Game over!")
-		// TODO: Implement game over state
+		killerName, ok := g.ecs.GetName(killerID)
+		if !ok {
+			killerName = "something"
+		}
+		g.gameOver(fmt.Sprintf("Killed by %s", killerName))
 		return
 	}
 
+	if killerID == g.PlayerID {
+		g.stats.Kills++
+	}
+
 	// Turn entity into a corpse
 	g.ecs.RemoveComponents(entityID,
 		components.CTurnActor,
 		components.CAITag,
+		components.CAIMemory,
+		components.CAIState,
+		components.CConfused,
 		components.CBlocksMovement,
 		components.CHealth,
 	)
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"math/rand"
+
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+)
+
+// bspGenerator recursively splits the map in two until the parts are about
+// the size of a room, and carves a room in each of them. The two halves of
+// every split are joined by a tunnel between their closest rooms, and a few
+// more tunnels add loops, so that levels are dense and well connected.
+type bspGenerator struct{}
+
+// Generate implements Generator.Generate. Rooms are returned in split order,
+// so that the first and last ones are at opposite ends of the map.
+func (gen bspGenerator) Generate(m *Map, rng *rand.Rand, cfg *config.GameConfig) []Region {
+	rooms := gen.split(m, rng, cfg, NewRect(0, 0, m.Width-1, m.Height-1))
+
+	// Extra tunnels between neighboring rooms make loops
+	for range len(rooms) / 3 {
+		a := rooms[rng.Intn(len(rooms))]
+		var others []Rect
+		for _, r := range rooms {
+			if r != a {
+				others = append(others, r)
+			}
+		}
+		if len(others) == 0 {
+			break
+		}
+		b := closestRooms([]Rect{a}, others, rng)
+		createLTunnel(m.Grid, rng, a.Center(), b[1].Center())
+	}
+	return rectRegions(rooms)
+}
+
+// split carves rooms in the area, splitting it first if it is large enough,
+// and returns them.
+func (gen bspGenerator) split(m *Map, rng *rand.Rand, cfg *config.GameConfig, area Rect) []Rect {
+	w, h := area.X2-area.X1, area.Y2-area.Y1
+	// Parts must hold a room of the smallest size
+	minPart := cfg.RoomMinSize + 1
+	canSplitX, canSplitY := w >= 2*minPart, h >= 2*minPart
+	// Parts that can hold the largest rooms are sometimes left whole
+	if w <= cfg.RoomMaxSize+2 && h <= cfg.RoomMaxSize+2 && rng.Intn(3) == 0 {
+		canSplitX, canSplitY = false, false
+	}
+
+	var splitX bool
+	switch {
+	case canSplitX && canSplitY:
+		// Split across the longer side, to avoid thin parts
+		switch {
+		case w*4 > h*5:
+			splitX = true
+		case h*4 > w*5:
+			splitX = false
+		default:
+			splitX = rng.Intn(2) == 0
+		}
+	case canSplitX:
+		splitX = true
+	case canSplitY:
+		splitX = false
+	default:
+		return []Rect{gen.carveRoom(m, rng, cfg, area)}
+	}
+
+	var first, second Rect
+	if splitX {
+		x := area.X1 + minPart + rng.Intn(w-2*minPart+1)
+		first = Rect{X1: area.X1, Y1: area.Y1, X2: x, Y2: area.Y2}
+		second = Rect{X1: x, Y1: area.Y1, X2: area.X2, Y2: area.Y2}
+	} else {
+		y := area.Y1 + minPart + rng.Intn(h-2*minPart+1)
+		first = Rect{X1: area.X1, Y1: area.Y1, X2: area.X2, Y2: y}
+		second = Rect{X1: area.X1, Y1: y, X2: area.X2, Y2: area.Y2}
+	}
+	firstRooms := gen.split(m, rng, cfg, first)
+	secondRooms := gen.split(m, rng, cfg, second)
+
+	pair := closestRooms(firstRooms, secondRooms, rng)
+	createLTunnel(m.Grid, rng, pair[0].Center(), pair[1].Center())
+	return append(firstRooms, secondRooms...)
+}
+
+// carveRoom carves a room of random size and position within the area.
+func (gen bspGenerator) carveRoom(m *Map, rng *rand.Rand, cfg *config.GameConfig, area Rect) Rect {
+	w, h := area.X2-area.X1, area.Y2-area.Y1
+	rw := cfg.RoomMinSize + rng.Intn(min(cfg.RoomMaxSize, w)-cfg.RoomMinSize+1)
+	rh := cfg.RoomMinSize + rng.Intn(min(cfg.RoomMaxSize, h)-cfg.RoomMinSize+1)
+	room := NewRect(area.X1+rng.Intn(w-rw+1), area.Y1+rng.Intn(h-rh+1), rw, rh)
+	createRoom(m.Grid, room)
+	return room
+}
+
+// closestRooms returns a room from each list, such that their centers are as
+// close as possible. Ties are broken at random.
+func closestRooms(as, bs []Rect, rng *rand.Rand) [2]Rect {
+	var best [2]Rect
+	bestDist, ties := -1, 0
+	for _, a := range as {
+		for _, b := range bs {
+			d := paths.DistanceManhattan(a.Center(), b.Center())
+			switch {
+			case bestDist < 0 || d < bestDist:
+				best, bestDist, ties = [2]Rect{a, b}, d, 1
+			case d == bestDist:
+				ties++
+				if rng.Intn(ties) == 0 {
+					best = [2]Rect{a, b}
+				}
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,229 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"codeberg.org/anaseto/gruid/rl"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+)
+
+// caveRules are the cellular automaton rules growing caves: the first ones
+// open large caverns while removing isolated walls, and the last ones smooth
+// them.
+var caveRules = []rl.CellularAutomataRule{
+	{WCutoff1: 5, WCutoff2: 2, WallsOutOfRange: true, Reps: 4},
+	{WCutoff1: 5, WCutoff2: 25, WallsOutOfRange: true, Reps: 3},
+}
+
+const (
+	caveWallRatio   = 0.45 // initial proportion of walls
+	caveMinFloor    = 4    // floor tiles below which a room is carved
+	caveMinPocket   = 4    // floor tiles below which a pocket is filled in
+	caveGrowRetries = 10   // number of attempts at growing enough floor
+)
+
+// caveGenerator grows caves with a cellular automaton, and joins the
+// disconnected parts to the largest one with tunnels. The caves are divided
+// into regions of about the size of a room, from left to right.
+type caveGenerator struct{}
+
+// Generate implements Generator.Generate.
+func (caveGenerator) Generate(m *Map, rng *rand.Rand, cfg *config.GameConfig) []Region {
+	growCaves(m, rng, cfg)
+	connectFloor(m, rng)
+	return caveRegions(m, cfg, nil)
+}
+
+// hybridGenerator grows caves around clusters of rooms laid out like the
+// bspGenerator does, one cluster in each half of a wide enough map.
+type hybridGenerator struct{}
+
+// Generate implements Generator.Generate. Regions are sorted from left to
+// right, so that the first and last ones are at opposite ends of the map.
+func (hybridGenerator) Generate(m *Map, rng *rand.Rand, cfg *config.GameConfig) []Region {
+	growCaves(m, rng, cfg)
+
+	// Clusters are a third of the map wide, leaving room for the caves
+	w := min(max(cfg.RoomMaxSize+2, m.Width/3), m.Width-1)
+	h := min(max(cfg.RoomMaxSize+2, m.Height*2/3), m.Height-1)
+	n := min(2, (m.Width-1)/w)
+	var clusters []Rect
+	var regions []Region
+	for i := range n {
+		start, end := i*m.Width/n, min((i+1)*m.Width/n, m.Width-1)
+		area := NewRect(start+rng.Intn(end-start-w+1), rng.Intn(m.Height-h), w, h)
+		for y := area.Y1; y <= area.Y2; y++ {
+			for x := area.X1; x <= area.X2; x++ {
+				m.Grid.Set(gruid.Point{X: x, Y: y}, WallCell)
+			}
+		}
+		clusters = append(clusters, area)
+		regions = append(regions, rectRegions(bspGenerator{}.split(m, rng, cfg, area))...)
+	}
+
+	connectFloor(m, rng)
+	regions = append(regions, caveRegions(m, cfg, clusters)...)
+	slices.SortStableFunc(regions, func(a, b Region) int {
+		return a.Center().X - b.Center().X
+	})
+	return regions
+}
+
+// growCaves fills the map with caves, keeping its edges as walls. If the
+// automaton leaves too little floor, it tries again, and as a last resort
+// carves a room in the middle of the map.
+func growCaves(m *Map, rng *rand.Rand, cfg *config.GameConfig) {
+	mg := rl.MapGen{Rand: rng, Grid: m.Grid}
+	for range caveGrowRetries {
+		mg.CellularAutomataCave(WallCell, FloorCell, caveWallRatio, caveRules)
+		for x := 0; x < m.Width; x++ {
+			m.Grid.Set(gruid.Point{X: x, Y: 0}, WallCell)
+			m.Grid.Set(gruid.Point{X: x, Y: m.Height - 1}, WallCell)
+		}
+		for y := 0; y < m.Height; y++ {
+			m.Grid.Set(gruid.Point{X: 0, Y: y}, WallCell)
+			m.Grid.Set(gruid.Point{X: m.Width - 1, Y: y}, WallCell)
+		}
+		if floorCount(m) >= m.Width*m.Height/4 {
+			return
+		}
+	}
+	if floorCount(m) < caveMinFloor {
+		size := cfg.RoomMinSize
+		createRoom(m.Grid, NewRect((m.Width-size)/2, (m.Height-size)/2, size, size))
+	}
+}
+
+// floorCount returns the number of floor tiles of the map.
+func floorCount(m *Map) int {
+	n := 0
+	it := m.Grid.Iterator()
+	for it.Next() {
+		if it.Cell() == FloorCell {
+			n++
+		}
+	}
+	return n
+}
+
+// floorPather implements paths.Pather for walking over the floor of a map
+// being generated.
+type floorPather struct {
+	m  *Map
+	nb paths.Neighbors
+}
+
+// Neighbors implements paths.Pather.Neighbors.
+func (fp *floorPather) Neighbors(p gruid.Point) []gruid.Point {
+	if !fp.m.isWalkable(p) {
+		return nil
+	}
+	return fp.nb.Cardinal(p, fp.m.isWalkable)
+}
+
+// walkableParts returns the connected parts of the walkable tiles of the map,
+// in map order, along with the index of the largest one. It returns -1 if
+// there are no walkable tiles.
+func walkableParts(m *Map) ([]Region, int) {
+	pr := paths.NewPathRange(m.Grid.Bounds())
+	pr.CCMapAll(&floorPather{m: m})
+
+	var parts []Region
+	index := make(map[int]int)
+	it := m.Grid.Iterator()
+	for it.Next() {
+		if !m.isWalkable(it.P()) {
+			continue
+		}
+		id := pr.CCMapAt(it.P())
+		i, ok := index[id]
+		if !ok {
+			i = len(parts)
+			index[id] = i
+			parts = append(parts, nil)
+		}
+		parts[i] = append(parts[i], it.P())
+	}
+
+	largest := -1
+	for i, part := range parts {
+		if largest < 0 || len(part) > len(parts[largest]) {
+			largest = i
+		}
+	}
+	return parts, largest
+}
+
+// closestTo returns the tile of the region closest to p.
+func (reg Region) closestTo(p gruid.Point) gruid.Point {
+	best := reg[0]
+	for _, q := range reg {
+		if paths.DistanceManhattan(p, q) < paths.DistanceManhattan(p, best) {
+			best = q
+		}
+	}
+	return best
+}
+
+// connectFloor makes the floor of the map a single connected area: small
+// pockets are filled in, and other parts are joined to the largest one with
+// a tunnel from a random tile to the closest floor of the largest part.
+func connectFloor(m *Map, rng *rand.Rand) {
+	parts, largest := walkableParts(m)
+	if largest < 0 {
+		return
+	}
+
+	// Pockets are filled in first, so that they cannot cut tunnels
+	for i, part := range parts {
+		if i != largest && len(part) < caveMinPocket {
+			for _, p := range part {
+				m.Grid.Set(p, WallCell)
+			}
+		}
+	}
+	joined := parts[largest]
+	for i, part := range parts {
+		if i != largest && len(part) >= caveMinPocket {
+			from := part[rng.Intn(len(part))]
+			createLTunnel(m.Grid, rng, from, joined.closestTo(from))
+			joined = append(joined, part...)
+		}
+	}
+}
+
+// caveRegions divides the floor of the map outside the given areas into
+// square regions of RoomMaxSize, from left to right. Regions with less floor
+// than the smallest room are left out. If none is left, the whole floor makes
+// a single region.
+func caveRegions(m *Map, cfg *config.GameConfig, exclude []Rect) []Region {
+	var regions []Region
+	var floor Region
+	size := cfg.RoomMaxSize
+	minFloor := (cfg.RoomMinSize - 1) * (cfg.RoomMinSize - 1)
+	for x0 := 0; x0 < m.Width; x0 += size {
+		for y0 := 0; y0 < m.Height; y0 += size {
+			var reg Region
+			for y := y0; y < min(y0+size, m.Height); y++ {
+				for x := x0; x < min(x0+size, m.Width); x++ {
+					p := gruid.Point{X: x, Y: y}
+					if m.Grid.At(p) != FloorCell || slices.ContainsFunc(exclude, func(r Rect) bool { return r.Contains(p) }) {
+						continue
+					}
+					reg = append(reg, p)
+				}
+			}
+			floor = append(floor, reg...)
+			if len(reg) >= minFloor {
+				regions = append(regions, reg)
+			}
+		}
+	}
+	if len(regions) == 0 && len(floor) > 0 {
+		regions = append(regions, floor)
+	}
+	return regions
+}
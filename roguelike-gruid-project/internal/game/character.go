@@ -0,0 +1,98 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// updateCharacter handles input on the character screen.
+func (md *Model) updateCharacter(msg gruid.Msg) gruid.Effect {
+	key, ok := msg.(gruid.MsgKeyDown)
+	if !ok {
+		return nil
+	}
+
+	if keymap.action(keymapCharacter, key.Key) == ActionClose || keymap.action(keymapNormal, key.Key) == ActionCharacter {
+		md.mode = modeNormal
+	}
+	return nil
+}
+
+// slotName returns the capitalized name of an equipment slot.
+func slotName(slot components.EquipSlot) string {
+	s := string(slot)
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// describeBonus returns a short description of equipment combat bonuses, such
+// as "+2 power, -5 evasion".
+func describeBonus(b components.CombatStats) string {
+	var parts []string
+	add := func(v int, name string) {
+		if v != 0 {
+			parts = append(parts, fmt.Sprintf("%+d %s", v, name))
+		}
+	}
+	add(b.Power, "power")
+	add(b.Defense, "defense")
+	add(b.Accuracy, "accuracy")
+	add(b.Evasion, "evasion")
+	add(b.CritChance, "crit")
+	return strings.Join(parts, ", ")
+}
+
+// drawCharacter draws the character screen: the player's equipment, and
+// their combat stats with and without it.
+func (md *Model) drawCharacter() {
+	g := md.game
+	md.grid.Fill(gruid.Cell{Rune: ' '})
+
+	textStyle := gruid.Style{Fg: ui.ColorUIText}
+	titleStyle := gruid.Style{Fg: ui.ColorUITitle}
+	lines := []gui.StyledText{}
+	if health, ok := g.ecs.GetHealth(g.PlayerID); ok {
+		lines = append(lines, gui.NewStyledText(fmt.Sprintf("HP %d/%d", health.CurrentHP, health.MaxHP), gruid.Style{Fg: healthColor(health)}))
+		lines = append(lines, gui.Text(""))
+	}
+
+	lines = append(lines, gui.NewStyledText("Equipment", titleStyle))
+	equipment, _ := g.ecs.GetEquipment(g.PlayerID)
+	for _, slot := range components.EquipSlots {
+		desc := "-"
+		if itemID := ecs.EntityID(equipment.Item(slot)); itemID != 0 {
+			desc, _ = g.ecs.GetName(itemID)
+			item, _ := g.ecs.GetEquippable(itemID)
+			if bonus := describeBonus(item.Bonus); bonus != "" {
+				desc += " (" + bonus + ")"
+			}
+		}
+		lines = append(lines, gui.NewStyledText(fmt.Sprintf("%-8s %s", slotName(slot), desc), textStyle))
+	}
+	lines = append(lines, gui.Text(""))
+
+	base, _ := g.ecs.GetCombatStats(g.PlayerID)
+	total := g.combatStats(g.PlayerID)
+	lines = append(lines, gui.NewStyledText(fmt.Sprintf("%-12s %5s %6s", "Stats", "Base", "Total"), titleStyle))
+	for _, stat := range []struct {
+		name        string
+		base, total int
+	}{
+		{"Power", base.Power, total.Power},
+		{"Defense", base.Defense, total.Defense},
+		{"Accuracy", base.Accuracy, total.Accuracy},
+		{"Evasion", base.Evasion, total.Evasion},
+		{"Crit chance", base.CritChance, total.CritChance},
+	} {
+		lines = append(lines, gui.NewStyledText(fmt.Sprintf("%-12s %5d %6d", stat.name, stat.base, stat.total), textStyle))
+	}
+	lines = append(lines, gui.Text(""))
+	lines = append(lines, gui.NewStyledText(keyHint(keymapCharacter, ActionClose, "Close"), gruid.Style{Fg: ui.ColorUIHighlight}))
+
+	md.drawCenteredBox("Character", lines)
+}
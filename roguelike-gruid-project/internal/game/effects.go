@@ -0,0 +1,160 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/sirupsen/logrus"
+)
+
+// teleportTries is the number of random positions tried when looking for a
+// free teleport destination.
+const teleportTries = 100
+
+// itemEffect applies the effect of a consumable item used by an entity, at the
+// given target for targeted effects. It returns false if the effect could not
+// happen, in which case the item is not used up.
+type itemEffect func(g *Game, userID ecs.EntityID, power int, target gruid.Point) bool
+
+// itemEffects maps each consumable effect to its handler.
+var itemEffects = map[components.ItemEffect]itemEffect{
+	components.EffectHeal:      (*Game).healEffect,
+	components.EffectTeleport:  (*Game).teleportEffect,
+	components.EffectConfuse:   (*Game).confuseEffect,
+	components.EffectLightning: (*Game).lightningEffect,
+}
+
+// healEffect restores up to power health points. It does nothing to entities
+// without health or already at full health.
+func (g *Game) healEffect(userID ecs.EntityID, power int, _ gruid.Point) bool {
+	health, ok := g.ecs.GetHealth(userID)
+	if !ok {
+		return false
+	}
+	if health.CurrentHP >= health.MaxHP {
+		if userID == g.PlayerID {
+			g.log.AddMessagef(ui.ColorUIText, "You are already at full health.")
+		}
+		return false
+	}
+
+	healed := min(power, health.MaxHP-health.CurrentHP)
+	health.CurrentHP += healed
+	g.ecs.AddComponent(userID, components.CHealth, health)
+	if userID == g.PlayerID {
+		g.log.AddMessagef(ui.ColorStatusGood, "You recover %d HP.", healed)
+	} else {
+		name, _ := g.ecs.GetName(userID)
+		g.log.AddMessagef(ui.ColorUIText, "The %s looks healthier.", name)
+	}
+	return true
+}
+
+// teleportEffect moves the user to a random walkable tile free of blocking
+// entities.
+func (g *Game) teleportEffect(userID ecs.EntityID, _ int, _ gruid.Point) bool {
+	pos, _ := g.ecs.GetPosition(userID)
+	for range teleportTries {
+		q := gruid.Point{X: g.rand.Intn(g.dungeon.Width), Y: g.rand.Intn(g.dungeon.Height)}
+		if q == pos || !g.dungeon.isWalkable(q) || len(g.spatialGrid.GetEntitiesAt(q)) > 0 {
+			continue
+		}
+		if err := g.ecs.MoveEntity(userID, q); err != nil {
+			logrus.Errorf("Failed to teleport entity %d: %v", userID, err)
+			return false
+		}
+		g.UpdateEntityPosition(userID, pos, q)
+		g.log.AddMessagef(ui.ColorSpecialItem, "You vanish and reappear elsewhere.")
+		return true
+	}
+	g.log.AddMessagef(ui.ColorUIText, "You feel a brief tug, but nothing happens.")
+	return false
+}
+
+// confuseEffect confuses the monster at the target for power turns.
+func (g *Game) confuseEffect(_ ecs.EntityID, power int, target gruid.Point) bool {
+	targetID, ok := g.monsterAt(target)
+	if !ok {
+		g.log.AddMessagef(ui.ColorUIText, "There is no one there to confuse.")
+		return false
+	}
+
+	g.ecs.AddComponents(targetID, components.Confused{Turns: power})
+	targetName, _ := g.ecs.GetName(targetID)
+	g.log.AddMessagef(ui.ColorConfusedMonster, "The %s looks confused.", targetName)
+	return true
+}
+
+// lightningEffect strikes the nearest enemy in view for power damage,
+// regardless of defense.
+func (g *Game) lightningEffect(userID ecs.EntityID, power int, _ gruid.Point) bool {
+	targetID, ok := g.nearestVisibleEnemy(userID)
+	if !ok {
+		g.log.AddMessagef(ui.ColorUIText, "There is no one in sight to strike.")
+		return false
+	}
+
+	targetName, _ := g.ecs.GetName(targetID)
+	health, _ := g.ecs.GetHealth(targetID)
+	health.CurrentHP -= power
+	g.ecs.AddComponent(targetID, components.CHealth, health)
+	g.log.AddMessagef(ui.ColorCriticalHit, "A lightning bolt strikes the %s for %d damage!", targetName, power)
+
+	userPos, _ := g.ecs.GetPosition(userID)
+	g.alertMonster(targetID, userPos)
+	if health.IsDead() {
+		g.handleEntityDeath(targetID, targetName, userID)
+	}
+	return true
+}
+
+// nearestVisibleEnemy returns the living monster nearest to the entity among
+// those in its field of view.
+func (g *Game) nearestVisibleEnemy(id ecs.EntityID) (ecs.EntityID, bool) {
+	fov, ok := g.ecs.GetFOV(id)
+	if !ok {
+		return 0, false
+	}
+	monsters := g.visibleMonsters(id, fov)
+	if len(monsters) == 0 {
+		return 0, false
+	}
+	return monsters[0], true
+}
+
+// monsterAt returns the monster at the given position.
+func (g *Game) monsterAt(p gruid.Point) (ecs.EntityID, bool) {
+	for _, id := range g.spatialGrid.GetEntitiesAt(p) {
+		if g.ecs.HasComponent(id, components.CAITag) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// confusedAction returns the action of a confused monster: a step in one of
+// the eight directions at random, attacking whatever stands there. The
+// confusion wears off over time.
+func (g *Game) confusedAction(id ecs.EntityID, pos gruid.Point, confused components.Confused) GameAction {
+	confused.Turns--
+	if confused.Turns <= 0 {
+		g.ecs.RemoveComponent(id, components.CConfused)
+		if playerFOV, ok := g.ecs.GetFOV(g.PlayerID); ok && playerFOV.IsVisible(pos, g.dungeon.Width) {
+			name, _ := g.ecs.GetName(id)
+			g.log.AddMessagef(ui.ColorUIText, "The %s is no longer confused.", name)
+		}
+	} else {
+		g.ecs.AddComponents(id, confused)
+	}
+
+	directions := []gruid.Point{
+		{X: -1}, {X: 1}, {Y: -1}, {Y: 1},
+		{X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: 1, Y: 1},
+	}
+	dir := directions[g.rand.Intn(len(directions))]
+	if !g.dungeon.isWalkable(pos.Add(dir)) {
+		return WaitAction{EntityID: id}
+	}
+	return MoveAction{Direction: dir, EntityID: id}
+}
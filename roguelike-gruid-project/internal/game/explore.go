@@ -0,0 +1,92 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// explore starts auto-exploration of the level, unless a monster is in view.
+func (md *Model) explore() (again bool, eff gruid.Effect, err error) {
+	g := md.game
+	if g.monstersInView() {
+		g.log.AddMessagef(ui.ColorUIText, "Not with monsters in view.")
+		return true, nil, nil
+	}
+	if g.exploreFrontier() == nil {
+		g.log.AddMessagef(ui.ColorUIText, "There is nothing left to explore here.")
+		return true, nil, nil
+	}
+
+	g.startAutoMove(autoExplore)
+	return false, nil, nil
+}
+
+// monstersInView reports whether the player sees any monster.
+func (g *Game) monstersInView() bool {
+	fov, ok := g.ecs.GetFOV(g.PlayerID)
+	return ok && len(g.visibleMonsters(g.PlayerID, fov)) > 0
+}
+
+// exploreFrontier returns the explored walkable tiles next to unexplored ones.
+func (g *Game) exploreFrontier() []gruid.Point {
+	var frontier []gruid.Point
+	nb := paths.Neighbors{}
+	it := g.dungeon.Grid.Iterator()
+	for it.Next() {
+		p := it.P()
+		if !g.dungeon.isWalkable(p) || !g.dungeon.IsExplored(p) {
+			continue
+		}
+		unexplored := nb.All(p, func(q gruid.Point) bool {
+			return g.dungeon.InBounds(q) && !g.dungeon.IsExplored(q)
+		})
+		if len(unexplored) > 0 {
+			frontier = append(frontier, p)
+		}
+	}
+	return frontier
+}
+
+// exploreAction returns the next auto-exploration action of the player,
+// planned anew at each step: picking up an item stepped on, or a step toward
+// the nearest unexplored frontier. It returns nil, with a message, when there
+// is nothing left to explore or no known way to get there, and nil without one
+// when the way is blocked.
+func (g *Game) exploreAction() GameAction {
+	pos, _ := g.ecs.GetPosition(g.PlayerID)
+	if pos != g.auto.From && len(g.ecs.GetEntitiesAtWithComponents(pos, components.CItem)) > 0 {
+		if inv, _ := g.ecs.GetInventory(g.PlayerID); !inv.Full() {
+			return PickupAction{EntityID: g.PlayerID}
+		}
+	}
+
+	frontier := g.exploreFrontier()
+	if frontier == nil {
+		g.log.AddMessagef(ui.ColorUIText, "There is nothing left to explore here.")
+		return nil
+	}
+	maxCost := g.dungeon.Width * g.dungeon.Height
+	pr := g.pathRange()
+	pr.DijkstraMap(&travelPather{g: g}, frontier, maxCost)
+
+	best, bestCost := pos, pr.DijkstraMapAt(pos)
+	if bestCost > maxCost {
+		g.log.AddMessagef(ui.ColorUIText, "You can't reach the unexplored areas.")
+		return nil
+	}
+	nb := paths.Neighbors{}
+	for _, q := range nb.All(pos, g.dungeon.isWalkable) {
+		if len(g.spatialGrid.GetEntitiesAt(q)) > 0 {
+			continue
+		}
+		if c := pr.DijkstraMapAt(q); c < bestCost {
+			best, bestCost = q, c
+		}
+	}
+	if best == pos {
+		return nil
+	}
+	return MoveAction{Direction: best.Sub(pos), EntityID: g.PlayerID}
+}
@@ -2,8 +2,11 @@ package game
 
 import (
 	"math/rand"
-	"time"
 
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/combat"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/log"
@@ -14,39 +17,96 @@ import (
 type Game struct {
 	Depth           int
 	waitingForInput bool
+	state           GameState
+	stats           runStats
+	noise           noise    // loudest noise made since the last player input
+	auto            autoMove // sequence of queued player actions in progress
 
 	dungeon     *Map
+	levels      map[int]*Level // visited levels other than the current one, by depth
 	ecs         *ecs.ECS
 	spatialGrid *SpatialGrid
+	paths       *paths.PathRange // pathfinding scratch space, see pathRange
 
 	PlayerID  ecs.EntityID
 	turnQueue *turn.TurnQueue
 	log       *log.MessageLog
 
-	rand *rand.Rand
+	rng      *rngSource
+	rand     *rand.Rand
+	resolver combat.Resolver // decides the result of attacks
+
+	rules config.Rules // settings the game was started with
 }
 
-func NewGame() *Game {
-	return &Game{
+// NewGame creates a new game whose randomness is entirely derived from the
+// given seed.
+func NewGame(seed int64) *Game {
+	g := &Game{
 		ecs:         ecs.NewECS(),
+		levels:      make(map[int]*Level),
 		turnQueue:   turn.NewTurnQueue(),
 		log:         log.NewMessageLog(),
-		spatialGrid: NewSpatialGrid(config.DungeonWidth, config.DungeonHeight),
+		spatialGrid: NewSpatialGrid(config.Config.DungeonWidth, config.Config.DungeonHeight),
+		rules:       config.Config.Rules,
+		resolver:    combat.DefaultResolver(),
 	}
+	g.seedRNG(seed)
+	return g
 }
 
-// InitLevel initializes a new game level
-func (g *Game) InitLevel() {
-	if g.rand == nil {
-		g.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+// noise is a sound made in the dungeon. Sleeping monsters within its radius
+// may wake up.
+type noise struct {
+	Pos    gruid.Point
+	Radius int
+}
+
+// Noise radius of player actions.
+const (
+	stepNoise   = 3
+	attackNoise = 8
+)
+
+// makeNoise records a noise made at pos, unless a louder one was already made
+// this turn.
+func (g *Game) makeNoise(pos gruid.Point, radius int) {
+	if radius >= g.noise.Radius {
+		g.noise = noise{Pos: pos, Radius: radius}
 	}
+}
 
+// InitLevel initializes a new game level
+func (g *Game) InitLevel() {
 	g.Depth = 1
+	g.stats.MaxDepth = 1
 
 	// Clear the spatial grid for the new level
 	g.spatialGrid.Clear()
 
-	g.dungeon = NewMap(config.DungeonWidth, config.DungeonHeight)
-	playerStart := g.dungeon.generateMap(g, config.DungeonWidth, config.DungeonHeight)
+	g.dungeon = NewMap(config.Config.DungeonWidth, config.Config.DungeonHeight)
+	playerStart := g.dungeon.generateMap(g)
 	g.SpawnPlayer(playerStart)
 }
+
+// seedRNG sets up the game random number generator with the given seed. Every
+// system must draw from g.rand, so that a seed always produces the same run.
+func (g *Game) seedRNG(seed int64) {
+	g.rng = newRNGSource(seed)
+	g.rand = rand.New(g.rng)
+}
+
+// ECS returns the entity component system holding the game entities.
+func (g *Game) ECS() *ecs.ECS {
+	return g.ecs
+}
+
+// Dungeon returns the map of the current level.
+func (g *Game) Dungeon() *Map {
+	return g.dungeon
+}
+
+// Seed returns the seed of the current run.
+func (g *Game) Seed() int64 {
+	return g.rng.seed
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"fmt"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/sirupsen/logrus"
+)
+
+// runStats holds statistics about the current run, shown on the death screen.
+type runStats struct {
+	Turns        int    // player turns taken
+	Kills        int    // monsters killed by the player
+	MaxDepth     int    // deepest level reached
+	CauseOfDeath string // empty while the player is alive
+}
+
+// gameOver ends the current run: the player stops acting and the turn queue
+// is no longer processed.
+func (g *Game) gameOver(cause string) {
+	g.state = StateGameOver
+	g.stats.CauseOfDeath = cause
+	g.waitingForInput = false
+
+	if actor, ok := g.ecs.GetTurnActor(g.PlayerID); ok {
+		actor.Alive = false
+		g.ecs.AddComponent(g.PlayerID, components.CTurnActor, actor)
+	}
+	logrus.Infof("Game over: %s after %d turns", cause, g.stats.Turns)
+}
+
+// checkGameOver switches to the death screen once the game is over.
+func (md *Model) checkGameOver() {
+	if md.game.state == StateGameOver && md.mode != modeGameOver {
+		md.mode = modeGameOver
+		md.deleteSave()
+		md.writeRecording()
+	}
+}
+
+// newRun discards the current game and starts a fresh one. The new seed is
+// drawn from the previous run, so that a sequence of runs is reproducible.
+func (md *Model) newRun() {
+	resolver := md.game.resolver
+	md.game = NewGame(md.game.rand.Int63())
+	md.game.SetDamageResolver(resolver)
+	md.mode = modeNormal
+	md.msgHistory = nil
+	md.inventory = nil
+	md.targeting = nil
+	md.startGame()
+}
+
+// updateGameOver handles input on the death screen: start a new run or quit.
+func (md *Model) updateGameOver(msg gruid.Msg) gruid.Effect {
+	key, ok := msg.(gruid.MsgKeyDown)
+	if !ok {
+		return nil
+	}
+
+	switch keymap.action(keymapGameOver, key.Key) {
+	case ActionNewRun:
+		md.newRun()
+	case ActionQuit:
+		md.writeRecording()
+		return md.end()
+	}
+	return nil
+}
+
+// drawGameOver draws the death screen with a summary of the run.
+func (md *Model) drawGameOver() {
+	g := md.game
+	md.grid.Fill(gruid.Cell{Rune: ' '})
+
+	textStyle := gruid.Style{Fg: ui.ColorUIText}
+	lines := []gui.StyledText{
+		gui.NewStyledText(g.stats.CauseOfDeath, gruid.Style{Fg: ui.ColorDeath}),
+		gui.Text(""),
+		gui.NewStyledText(fmt.Sprintf("Turns survived: %d", g.stats.Turns), textStyle),
+		gui.NewStyledText(fmt.Sprintf("Depth reached:  %d", g.stats.MaxDepth), textStyle),
+		gui.NewStyledText(fmt.Sprintf("Monsters slain: %d", g.stats.Kills), textStyle),
+		gui.NewStyledText(fmt.Sprintf("Seed:           %d", g.Seed()), textStyle),
+		gui.Text(""),
+		gui.NewStyledText(joinHints(
+			keyHint(keymapGameOver, ActionNewRun, "New run"),
+			keyHint(keymapGameOver, ActionQuit, "Quit")), gruid.Style{Fg: ui.ColorUIHighlight}),
+	}
+	md.drawCenteredBox("You Died", lines)
+}
+
+// drawCenteredBox draws lines of text in a titled box at the center of the
+// screen.
+func (md *Model) drawCenteredBox(title string, lines []gui.StyledText) {
+	w, h := 0, len(lines)+2
+	for _, l := range lines {
+		w = max(w, l.Size().X)
+	}
+	w += 4
+
+	size := md.grid.Size()
+	x, y := (size.X-w)/2, (size.Y-h)/2
+	area := md.grid.Slice(gruid.NewRange(x, y, x+w, y+h))
+	gui.Box{
+		Style: gruid.Style{Fg: ui.ColorUIBorder},
+		Title: gui.NewStyledText(title, gruid.Style{Fg: ui.ColorUITitle}),
+	}.Draw(area)
+
+	content := area.Slice(area.Range().Shift(2, 1, -2, -1))
+	for i, l := range lines {
+		l.Draw(content.Slice(content.Range().Line(i)))
+	}
+}
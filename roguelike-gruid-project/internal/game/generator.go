@@ -0,0 +1,65 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+)
+
+// Generator lays out the terrain of a level.
+type Generator interface {
+	// Generate carves the level into the map, filled with walls beforehand,
+	// and returns its regions, in which monsters and items are spawned. The
+	// player starts in the first region, and the stairs down are placed in
+	// the last one.
+	Generate(m *Map, rng *rand.Rand, cfg *config.GameConfig) []Region
+}
+
+// generators holds the level generators by configuration name.
+var generators = map[string]Generator{
+	"rooms":  roomsGenerator{},
+	"bsp":    bspGenerator{},
+	"caves":  caveGenerator{},
+	"hybrid": hybridGenerator{},
+}
+
+// autoGenerators are the generators used in turn, one level after the other,
+// when the configuration lets the depth choose.
+var autoGenerators = []string{"rooms", "bsp", "caves", "hybrid"}
+
+// generatorFor returns the name of the generator for a level at the given
+// depth: the configured one, or one depending on the depth.
+func generatorFor(depth int) string {
+	if name := config.Config.Generator; name != config.AutoGenerator {
+		return name
+	}
+	return autoGenerators[(depth-1)%len(autoGenerators)]
+}
+
+// roomsGenerator places rooms at random, dropping those overlapping others,
+// and joins each room to the previous one with an L-shaped tunnel.
+type roomsGenerator struct{}
+
+// Generate implements Generator.Generate.
+func (roomsGenerator) Generate(m *Map, rng *rand.Rand, cfg *config.GameConfig) []Region {
+	var rooms []Rect
+	for range cfg.MaxRooms {
+		w := rng.Intn(cfg.RoomMaxSize-cfg.RoomMinSize+1) + cfg.RoomMinSize
+		h := rng.Intn(cfg.RoomMaxSize-cfg.RoomMinSize+1) + cfg.RoomMinSize
+		x := rng.Intn(m.Width - w - 1)  // -1 to ensure room fits
+		y := rng.Intn(m.Height - h - 1) // -1 to ensure room fits
+
+		newRoom := NewRect(x, y, w, h)
+		if slices.ContainsFunc(rooms, newRoom.Intersects) {
+			continue
+		}
+		createRoom(m.Grid, newRoom)
+		if len(rooms) > 0 {
+			// Connect to the previous room's center
+			createLTunnel(m.Grid, rng, rooms[len(rooms)-1].Center(), newRoom.Center())
+		}
+		rooms = append(rooms, newRoom)
+	}
+	return rectRegions(rooms)
+}
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// keyName returns the short name of a key shown to the player.
+func keyName(key gruid.Key) string {
+	switch key {
+	case gruid.KeyEscape:
+		return "esc"
+	case gruid.KeySpace:
+		return "space"
+	case gruid.KeyArrowLeft, gruid.KeyArrowRight, gruid.KeyArrowUp, gruid.KeyArrowDown:
+		return strings.ToLower(strings.TrimPrefix(string(key), "Arrow"))
+	}
+	if key.IsRune() {
+		return string(key)
+	}
+	return strings.ToLower(string(key))
+}
+
+// keyHint returns a short key help such as "[tab] next", for the first key
+// bound to the action in the given mode. It returns an empty string if no key
+// is bound to the action.
+func keyHint(mode string, action playerAction, label string) string {
+	keys := keymap.keys(mode, action)
+	if len(keys) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%s] %s", keyName(keys[0]), label)
+}
+
+// joinHints joins non-empty key hints.
+func joinHints(hints ...string) string {
+	var parts []string
+	for _, h := range hints {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// helpLines returns the lines of the help screen, generated from the active
+// key bindings.
+func helpLines() []gui.StyledText {
+	titleStyle := gruid.Style{Fg: ui.ColorUITitle}
+	textStyle := gruid.Style{Fg: ui.ColorUIText}
+	var lines []gui.StyledText
+	for _, mode := range keymapModes {
+		lines = append(lines, gui.NewStyledText(mode.Title, titleStyle))
+		for _, action := range mode.Actions {
+			keys := keymap.keys(mode.Name, action)
+			if len(keys) == 0 {
+				continue
+			}
+			names := make([]string, len(keys))
+			for i, key := range keys {
+				names[i] = keyName(key)
+			}
+			desc := actionInfos[action].Help
+			if help, ok := mode.Help[action]; ok {
+				desc = help
+			}
+			if mode.Name == keymapRun {
+				desc = "Run" + strings.TrimPrefix(desc, "Move")
+			}
+			text := fmt.Sprintf("  %-22s %s", strings.Join(names, " "), desc)
+			lines = append(lines, gui.NewStyledText(text, textStyle))
+		}
+		lines = append(lines, gui.Text(""))
+	}
+	lines = append(lines, gui.NewStyledText("Mouse", titleStyle))
+	lines = append(lines, gui.NewStyledText(fmt.Sprintf("  %-22s %s", "hover", "Describe a tile and show the way there"), textStyle))
+	lines = append(lines, gui.NewStyledText(fmt.Sprintf("  %-22s %s", "click", "Travel to an explored tile"), textStyle))
+	return lines
+}
+
+// openHelp switches to the help screen.
+func (md *Model) openHelp() {
+	quit := append(keymap.keys(keymapHelp, ActionClose), keymap.keys(keymapNormal, ActionHelp)...)
+	md.help = gui.NewPager(gui.PagerConfig{
+		Grid: md.grid,
+		Box: &gui.Box{
+			Style:  gruid.Style{Fg: ui.ColorUIBorder},
+			Title:  gui.NewStyledText("Help", gruid.Style{Fg: ui.ColorUITitle}),
+			Footer: gui.NewStyledText(keyHint(keymapHelp, ActionClose, "Close"), gruid.Style{Fg: ui.ColorUIHighlight}),
+		},
+		Keys: gui.PagerKeys{Quit: quit},
+	})
+	md.help.SetLines(helpLines())
+	md.grid.Fill(gruid.Cell{Rune: ' '})
+	md.mode = modeHelp
+}
+
+// updateHelp handles input while the help screen is shown.
+func (md *Model) updateHelp(msg gruid.Msg) gruid.Effect {
+	md.help.Update(msg)
+	if md.help.Action() == gui.PagerQuit {
+		md.help = nil
+		md.mode = modeNormal
+	}
+	return nil
+}
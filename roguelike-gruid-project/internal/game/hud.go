@@ -0,0 +1,114 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+const (
+	playerHPBarWidth  = 10
+	monsterHPBarWidth = 5
+)
+
+// healthColor returns the status color matching the given health ratio.
+func healthColor(h components.Health) gruid.Color {
+	switch {
+	case h.CurrentHP*10 > h.MaxHP*6:
+		return ui.ColorHealthOk
+	case h.CurrentHP*10 > h.MaxHP*3:
+		return ui.ColorHealthWounded
+	default:
+		return ui.ColorHealthCritical
+	}
+}
+
+// healthBar returns a text bar of the given width filled proportionally to the
+// remaining health.
+func healthBar(h components.Health, width int) string {
+	filled := 0
+	if h.MaxHP > 0 {
+		filled = max(0, min(width, (h.CurrentHP*width+h.MaxHP-1)/h.MaxHP))
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// visibleMonsters returns the living monsters in the viewer's field of view,
+// nearest first.
+func (g *Game) visibleMonsters(viewerID ecs.EntityID, fov *components.FOV) []ecs.EntityID {
+	viewerPos, _ := g.ecs.GetPosition(viewerID)
+	visible := g.spatialGrid.GetVisibleEntities(fov.GetVisiblePoints(g.dungeon.Width))
+
+	monsters := make([]ecs.EntityID, 0, len(visible))
+	for _, id := range visible {
+		if id != viewerID && g.ecs.HasComponent(id, components.CAITag) && g.ecs.HasComponent(id, components.CHealth) {
+			monsters = append(monsters, id)
+		}
+	}
+
+	distance := func(id ecs.EntityID) int {
+		pos, _ := g.ecs.GetPosition(id)
+		return paths.DistanceChebyshev(viewerPos, pos)
+	}
+	slices.SortFunc(monsters, func(a, b ecs.EntityID) int {
+		if da, db := distance(a), distance(b); da != db {
+			return da - db
+		}
+		return int(a - b)
+	})
+	return monsters
+}
+
+// drawText draws text in the line starting at column x, and returns the column
+// following the drawn text.
+func drawText(line gruid.Grid, x int, text string, style gruid.Style) int {
+	rg := line.Range()
+	return x + gui.NewStyledText(text, style).Draw(line.Slice(rg.Shift(x, 0, 0, 0))).Size().X
+}
+
+// drawHUD draws the status bar: player health, depth and game time on the
+// first line, and the visible monsters with their health on the second.
+func (md *Model) drawHUD(playerFOV *components.FOV) {
+	g := md.game
+	md.hud.Fill(gruid.Cell{Rune: ' '})
+
+	rg := md.hud.Range()
+	textStyle := gruid.Style{Fg: ui.ColorUIText}
+
+	status := md.hud.Slice(rg.Line(0))
+	x := 0
+	if health, ok := g.ecs.GetHealth(g.PlayerID); ok {
+		x = drawText(status, x, "HP ", textStyle)
+		hp := fmt.Sprintf("%s %d/%d", healthBar(health, playerHPBarWidth), health.CurrentHP, health.MaxHP)
+		x = drawText(status, x, hp, gruid.Style{Fg: healthColor(health)})
+	}
+	stats := g.combatStats(g.PlayerID)
+	x = drawText(status, x, fmt.Sprintf("  Pow %d Def %d", stats.Power, stats.Defense), textStyle)
+	drawText(status, x, fmt.Sprintf("  Depth %d  Time %d  Seed %d", g.Depth, g.turnQueue.CurrentTime, g.Seed()), textStyle)
+
+	if rg.Size().Y < 2 {
+		return
+	}
+	monsters := md.hud.Slice(rg.Line(1))
+	x = 0
+	for _, id := range g.visibleMonsters(g.PlayerID, playerFOV) {
+		if x >= rg.Size().X {
+			break
+		}
+		health, _ := g.ecs.GetHealth(id)
+		renderable, _ := g.ecs.GetRenderable(id)
+		color := monsterColor(g.ecs, id, renderable.Color)
+
+		x = drawText(monsters, x, string(renderable.Glyph), gruid.Style{Fg: color})
+		x = drawText(monsters, x, " "+g.describeMonster(id)+" ", textStyle)
+		x = drawText(monsters, x, healthBar(health, monsterHPBarWidth), gruid.Style{Fg: healthColor(health)})
+		x += 2
+	}
+}
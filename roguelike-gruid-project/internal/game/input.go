@@ -2,26 +2,8 @@ package game
 
 import "codeberg.org/anaseto/gruid"
 
-var KEYS_NORMAL = map[gruid.Key]playerAction{
-	gruid.KeyArrowLeft:  ActionW,
-	gruid.KeyArrowDown:  ActionS,
-	gruid.KeyArrowUp:    ActionN,
-	gruid.KeyArrowRight: ActionE,
-	"h":                 ActionW,
-	"j":                 ActionS,
-	"k":                 ActionN,
-	"l":                 ActionE,
-	"a":                 ActionW,
-	"s":                 ActionS,
-	"w":                 ActionN,
-	"d":                 ActionE,
-	"4":                 ActionW,
-	"2":                 ActionS,
-	"8":                 ActionN,
-	"6":                 ActionE,
-	"Q":                 ActionQuit,
-}
-
+// keyToDir returns the direction of a movement action, or the zero point for
+// other actions.
 func keyToDir(k playerAction) (p gruid.Point) {
 	switch k {
 	case ActionW:
@@ -32,6 +14,14 @@ func keyToDir(k playerAction) (p gruid.Point) {
 		p = gruid.Point{X: 0, Y: 1}
 	case ActionN:
 		p = gruid.Point{X: 0, Y: -1}
+	case ActionNW:
+		p = gruid.Point{X: -1, Y: -1}
+	case ActionNE:
+		p = gruid.Point{X: 1, Y: -1}
+	case ActionSW:
+		p = gruid.Point{X: -1, Y: 1}
+	case ActionSE:
+		p = gruid.Point{X: 1, Y: 1}
 	}
 	return p
 }
@@ -0,0 +1,200 @@
+package game
+
+import (
+	"fmt"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// inventoryPurpose is what choosing an item in the inventory menu does.
+type inventoryPurpose int
+
+const (
+	inventoryView  inventoryPurpose = iota // apply the chosen item as fits it
+	inventoryDrop                          // drop the chosen item
+	inventoryUse                           // use the chosen consumable item
+	inventoryEquip                         // equip or take off the chosen item
+)
+
+// inventoryMenu holds the state of the inventory menu, drawn over the map.
+type inventoryMenu struct {
+	menu    *gui.Menu
+	area    gruid.Grid // where the menu appears on the map
+	purpose inventoryPurpose
+	items   []ecs.EntityID // carried items, in menu order
+}
+
+// inventoryLetter returns the shortcut letter of the i-th inventory item.
+func inventoryLetter(i int) gruid.Key {
+	return gruid.Key(rune('a' + i))
+}
+
+// inventoryKeys returns the keys bound to the action in the inventory menu,
+// never nil: the menu would use its default keys instead, including letters.
+func inventoryKeys(action playerAction) []gruid.Key {
+	return append([]gruid.Key{}, keymap.keys(keymapInventory, action)...)
+}
+
+// openInventory shows the inventory menu for the given purpose, unless the
+// player carries nothing.
+func (md *Model) openInventory(purpose inventoryPurpose) {
+	g := md.game
+	inv, _ := g.ecs.GetInventory(g.PlayerID)
+	if len(inv.Items) == 0 {
+		g.log.AddMessagef(ui.ColorUIText, "You are not carrying anything.")
+		return
+	}
+
+	title := fmt.Sprintf("Inventory (%d/%d)", len(inv.Items), inv.Capacity)
+	switch purpose {
+	case inventoryDrop:
+		title = "Drop which item?"
+	case inventoryUse:
+		title = "Use which item?"
+	case inventoryEquip:
+		title = "Equip or remove which item?"
+	}
+	equipment, _ := g.ecs.GetEquipment(g.PlayerID)
+
+	im := &inventoryMenu{purpose: purpose}
+	entries := make([]gui.MenuEntry, 0, len(inv.Items))
+	w := len(title)
+	for i, id := range inv.Items {
+		itemID := ecs.EntityID(id)
+		name, _ := g.ecs.GetName(itemID)
+		key := inventoryLetter(i)
+		text := fmt.Sprintf("%s - %s", key, name)
+		if slot, worn := equipment.SlotOf(id); worn {
+			text += wornLabel(slot)
+		}
+		w = max(w, len(text))
+		entries = append(entries, gui.MenuEntry{
+			Text: gui.NewStyledText(text, gruid.Style{Fg: ui.ColorUIText}),
+			Keys: []gruid.Key{key},
+		})
+		im.items = append(im.items, itemID)
+	}
+
+	// Boxed menus are laid out from the origin of their grid, so the menu
+	// gets a grid of its own, copied onto the map when drawn.
+	size := md.viewport.Size()
+	w, h := min(w+2, size.X-2), min(len(entries)+2, size.Y-1)
+	im.area = md.viewport.Slice(gruid.NewRange(2, 1, 2+w, 1+h))
+	im.menu = gui.NewMenu(gui.MenuConfig{
+		Grid:    gruid.NewGrid(w, h),
+		Entries: entries,
+		// Letters select items, so the menu keys cannot use them
+		Keys: gui.MenuKeys{
+			Up:     inventoryKeys(ActionPrevEntry),
+			Down:   inventoryKeys(ActionNextEntry),
+			Left:   []gruid.Key{},
+			Right:  []gruid.Key{},
+			Invoke: inventoryKeys(ActionConfirm),
+			Quit:   inventoryKeys(ActionClose),
+		},
+		Box: &gui.Box{
+			Style: gruid.Style{Fg: ui.ColorUIBorder},
+			Title: gui.NewStyledText(title, gruid.Style{Fg: ui.ColorUITitle}),
+		},
+		Style: gui.MenuStyle{
+			Active: gruid.Style{Fg: ui.ColorUIHighlight},
+		},
+	})
+	md.inventory = im
+	md.mode = modeInventory
+}
+
+// closeInventory goes back to normal mode.
+func (md *Model) closeInventory() {
+	md.inventory = nil
+	md.mode = modeNormal
+}
+
+// updateInventory handles input while the inventory menu is shown.
+func (md *Model) updateInventory(msg gruid.Msg) gruid.Effect {
+	im := md.inventory
+	im.menu.Update(msg)
+
+	switch im.menu.Action() {
+	case gui.MenuQuit:
+		md.closeInventory()
+	case gui.MenuInvoke:
+		itemID := im.items[im.menu.Active()]
+		md.closeInventory()
+		switch im.purpose {
+		case inventoryDrop:
+			return md.queuePlayerAction(DropAction{EntityID: md.game.PlayerID, ItemID: itemID})
+		case inventoryUse:
+			return md.useItem(itemID)
+		case inventoryEquip:
+			return md.toggleEquipment(itemID)
+		case inventoryView:
+			if md.game.ecs.HasComponent(itemID, components.CEquippable) {
+				return md.toggleEquipment(itemID)
+			}
+			return md.useItem(itemID)
+		}
+	}
+	return nil
+}
+
+// wornLabel returns the inventory label suffix of an item equipped in slot.
+func wornLabel(slot components.EquipSlot) string {
+	if slot == components.SlotWeapon {
+		return " (wielded)"
+	}
+	return " (worn)"
+}
+
+// toggleEquipment queues taking off a carried item if it is equipped, and
+// equipping it otherwise.
+func (md *Model) toggleEquipment(itemID ecs.EntityID) gruid.Effect {
+	g := md.game
+	if !g.ecs.HasComponent(itemID, components.CEquippable) {
+		name, _ := g.ecs.GetName(itemID)
+		g.log.AddMessagef(ui.ColorUIText, "You cannot equip the %s.", name)
+		return nil
+	}
+	equipment, _ := g.ecs.GetEquipment(g.PlayerID)
+	if _, worn := equipment.SlotOf(int(itemID)); worn {
+		return md.queuePlayerAction(UnequipAction{EntityID: g.PlayerID, ItemID: itemID})
+	}
+	return md.queuePlayerAction(EquipAction{EntityID: g.PlayerID, ItemID: itemID})
+}
+
+// useItem queues the use of a carried item, if it can be used. Items with a
+// targeted effect are used once the player has chosen a target.
+func (md *Model) useItem(itemID ecs.EntityID) gruid.Effect {
+	g := md.game
+	consumable, ok := g.ecs.GetConsumable(itemID)
+	if !ok {
+		name, _ := g.ecs.GetName(itemID)
+		g.log.AddMessagef(ui.ColorUIText, "You cannot use the %s.", name)
+		return nil
+	}
+	if consumable.Effect.Targeted() {
+		md.startTargeting("Target?", func(target gruid.Point) gruid.Effect {
+			return md.queuePlayerAction(UseItemAction{EntityID: g.PlayerID, ItemID: itemID, Target: target})
+		})
+		return nil
+	}
+	return md.queuePlayerAction(UseItemAction{EntityID: g.PlayerID, ItemID: itemID})
+}
+
+// queuePlayerAction makes the player perform the action, ending their turn.
+func (md *Model) queuePlayerAction(action GameAction) gruid.Effect {
+	g := md.game
+	actor, _ := g.ecs.GetTurnActor(g.PlayerID)
+	actor.AddAction(action)
+	return md.EndTurn()
+}
+
+// drawInventory draws the inventory menu over the last drawn map.
+func (md *Model) drawInventory() {
+	im := md.inventory
+	im.area.Copy(im.menu.Draw())
+}
@@ -0,0 +1,379 @@
+package game
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"codeberg.org/anaseto/gruid"
+)
+
+// defaultKeys holds the default key bindings, which players can override with
+// their own file.
+//
+//go:embed keys.json
+var defaultKeys []byte
+
+// Input modes with configurable key bindings.
+const (
+	keymapNormal    = "normal"    // commands on the map
+	keymapRun       = "run"       // keys running in a direction
+	keymapTargeting = "targeting" // target selection and look mode
+	keymapCharacter = "character" // character screen
+	keymapMessages  = "messages"  // message history
+	keymapInventory = "inventory" // inventory menu
+	keymapHelp      = "help"      // help screen
+	keymapGameOver  = "gameover"  // death screen
+	keymapReplay    = "replay"    // replay controls
+)
+
+// unboundAction is the action name removing a default binding in a user
+// keymap file.
+const unboundAction = "none"
+
+// actionInfo describes an action that can be bound to keys.
+type actionInfo struct {
+	Name string // name in keymap files
+	Help string // description on the help screen
+}
+
+// actionInfos describes the actions that can be bound to keys.
+var actionInfos = map[playerAction]actionInfo{
+	ActionW:          {"move-west", "Move west"},
+	ActionS:          {"move-south", "Move south"},
+	ActionN:          {"move-north", "Move north"},
+	ActionE:          {"move-east", "Move east"},
+	ActionNW:         {"move-northwest", "Move north-west"},
+	ActionNE:         {"move-northeast", "Move north-east"},
+	ActionSW:         {"move-southwest", "Move south-west"},
+	ActionSE:         {"move-southeast", "Move south-east"},
+	ActionRest:       {"rest", "Rest until healed"},
+	ActionExplore:    {"explore", "Explore the level"},
+	ActionDescend:    {"descend", "Take stairs down"},
+	ActionAscend:     {"ascend", "Take stairs up"},
+	ActionPickup:     {"pickup", "Pick up an item"},
+	ActionInventory:  {"inventory", "Show the inventory"},
+	ActionDrop:       {"drop", "Drop an item"},
+	ActionUse:        {"use", "Use an item"},
+	ActionEquip:      {"equip", "Equip or remove an item"},
+	ActionCharacter:  {"character", "Show the character screen"},
+	ActionLook:       {"look", "Look around"},
+	ActionMessageLog: {"message-log", "Show the message history"},
+	ActionHelp:       {"help", "Show this help"},
+	ActionQuit:       {"quit", "Save and quit"},
+	ActionNextTarget: {"next-target", "Cycle through targets (with shift: backward)"},
+	ActionConfirm:    {"confirm", "Confirm the target"},
+	ActionCancel:     {"cancel", "Cancel"},
+
+	ActionClose:       {"close", "Close (the key opening the screen also closes it)"},
+	ActionSearch:      {"search", "Search the messages"},
+	ActionNextMatch:   {"next-match", "Jump to the next match"},
+	ActionPrevMatch:   {"previous-match", "Jump to the previous match"},
+	ActionNewRun:      {"new-run", "Start a new run"},
+	ActionPrevEntry:   {"previous-entry", "Select the previous entry"},
+	ActionNextEntry:   {"next-entry", "Select the next entry"},
+	ActionPause:       {"pause", "Pause or resume"},
+	ActionStep:        {"step", "Replay the next input while paused"},
+	ActionFaster:      {"faster", "Double the speed"},
+	ActionSlower:      {"slower", "Halve the speed"},
+	ActionFastForward: {"fast-forward", "Toggle fast-forward"},
+}
+
+// directionActions are the actions moving in a direction.
+var directionActions = []playerAction{
+	ActionW, ActionS, ActionN, ActionE, ActionNW, ActionNE, ActionSW, ActionSE,
+}
+
+// keymapMode describes an input mode with configurable key bindings.
+type keymapMode struct {
+	Name    string
+	Title   string                  // section title on the help screen
+	Actions []playerAction          // actions that can be bound, in help screen order
+	Help    map[playerAction]string // descriptions replacing the usual ones
+}
+
+// keymapModes lists the input modes with configurable key bindings.
+var keymapModes = []keymapMode{
+	{
+		Name:  keymapNormal,
+		Title: "Commands",
+		Actions: append(slices.Clone(directionActions),
+			ActionRest, ActionExplore, ActionDescend, ActionAscend,
+			ActionPickup, ActionInventory, ActionDrop, ActionUse, ActionEquip,
+			ActionCharacter, ActionLook, ActionMessageLog, ActionHelp, ActionQuit),
+	},
+	{
+		Name:    keymapRun,
+		Title:   "Running (direction keys with shift also run)",
+		Actions: directionActions,
+	},
+	{
+		Name:  keymapTargeting,
+		Title: "Targeting and looking",
+		Actions: append(slices.Clone(directionActions),
+			ActionNextTarget, ActionConfirm, ActionLook, ActionCancel),
+	},
+	{
+		Name:    keymapCharacter,
+		Title:   "Character screen",
+		Actions: []playerAction{ActionClose},
+	},
+	{
+		Name:    keymapMessages,
+		Title:   "Message history",
+		Actions: []playerAction{ActionSearch, ActionNextMatch, ActionPrevMatch, ActionClose},
+	},
+	{
+		Name:    keymapInventory,
+		Title:   "Inventory (letters choose an item)",
+		Actions: []playerAction{ActionPrevEntry, ActionNextEntry, ActionConfirm, ActionClose},
+		Help:    map[playerAction]string{ActionConfirm: "Choose the selected item"},
+	},
+	{
+		Name:    keymapHelp,
+		Title:   "Help screen",
+		Actions: []playerAction{ActionClose},
+	},
+	{
+		Name:    keymapGameOver,
+		Title:   "Death screen",
+		Actions: []playerAction{ActionNewRun, ActionQuit},
+		Help:    map[playerAction]string{ActionQuit: "Quit"},
+	},
+	{
+		Name:    keymapReplay,
+		Title:   "Replays",
+		Actions: []playerAction{ActionPause, ActionStep, ActionFaster, ActionSlower, ActionFastForward, ActionQuit},
+		Help:    map[playerAction]string{ActionQuit: "Stop the replay"},
+	},
+}
+
+// requiredActions are the actions that must keep at least one key, by mode,
+// so that players cannot lock themselves in.
+var requiredActions = map[string][]playerAction{
+	keymapNormal:    {ActionHelp, ActionQuit},
+	keymapInventory: {ActionClose},
+}
+
+// namedKeys are the valid key names other than single characters.
+var namedKeys = []gruid.Key{
+	gruid.KeyArrowDown, gruid.KeyArrowLeft, gruid.KeyArrowRight, gruid.KeyArrowUp,
+	gruid.KeyBackspace, gruid.KeyDelete, gruid.KeyEnd, gruid.KeyEnter,
+	gruid.KeyEscape, gruid.KeyHome, gruid.KeyInsert, gruid.KeyPageDown,
+	gruid.KeyPageUp, gruid.KeyTab,
+}
+
+// Keymap maps keys to actions, for each input mode.
+type Keymap map[string]map[gruid.Key]playerAction
+
+// keymap holds the active key bindings.
+var keymap = DefaultKeymap()
+
+// SetKeymap replaces the key bindings. It must be called before the game
+// starts.
+func SetKeymap(km Keymap) {
+	keymap = km
+}
+
+// ReadKeymap reads key bindings in JSON format: an object mapping mode names
+// to objects mapping key names to action names. The "none" action unbinds a
+// key. Every problem found is reported: unknown modes, keys and actions, and
+// keys bound twice in a mode.
+func ReadKeymap(r io.Reader) (Keymap, error) {
+	dec := json.NewDecoder(r)
+	var errs []error
+	km := Keymap{}
+	err := decodeObject(dec, func(modeName string) error {
+		mode, ok := findKeymapMode(modeName)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown mode %q", modeName))
+		}
+		bindings := map[gruid.Key]playerAction{}
+		km[modeName] = bindings
+		return decodeObject(dec, func(keyName string) error {
+			var name string
+			if err := dec.Decode(&name); err != nil {
+				return err
+			}
+			key := gruid.Key(keyName)
+			if !key.IsRune() && !key.In(namedKeys) {
+				errs = append(errs, fmt.Errorf("%s: unknown key %q", modeName, keyName))
+			}
+			if _, dup := bindings[key]; dup {
+				errs = append(errs, fmt.Errorf("%s: key %q is bound more than once", modeName, keyName))
+			}
+			action, ok := mode.action(name)
+			if !ok && mode.Name != "" {
+				errs = append(errs, fmt.Errorf("%s: unknown action %q", modeName, name))
+			}
+			bindings[key] = action
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode keymap: %w", err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid keymap: %w", err)
+	}
+	return km, nil
+}
+
+// decodeObject decodes a JSON object, calling fn with each of its keys to
+// decode the matching value.
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("expected an object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(tok.(string)); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// findKeymapMode returns the input mode with the given name.
+func findKeymapMode(name string) (keymapMode, bool) {
+	for _, mode := range keymapModes {
+		if mode.Name == name {
+			return mode, true
+		}
+	}
+	return keymapMode{}, false
+}
+
+// action returns the action with the given name that can be bound in the
+// mode. The "none" action is ActionNone.
+func (mode keymapMode) action(name string) (playerAction, bool) {
+	if name == unboundAction {
+		return ActionNone, true
+	}
+	for _, a := range mode.Actions {
+		if actionInfos[a].Name == name {
+			return a, true
+		}
+	}
+	return ActionNone, false
+}
+
+// DefaultKeymap returns the key bindings embedded in the binary.
+func DefaultKeymap() Keymap {
+	km, err := ReadKeymap(bytes.NewReader(defaultKeys))
+	if err == nil {
+		err = km.validate()
+	}
+	if err != nil {
+		panic(fmt.Sprintf("embedded keymap: %v", err))
+	}
+	return km
+}
+
+// LoadKeymap returns the default key bindings overridden by the file at path,
+// if it exists. Keys bound in the file replace the default bindings of the
+// same keys, and keys bound to "none" are unbound. It is an error for a key
+// to run in a direction while also being bound in normal mode.
+func LoadKeymap(path string) (Keymap, error) {
+	km := DefaultKeymap()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return km, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	user, err := ReadKeymap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	km.merge(user)
+	if err := km.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return km, nil
+}
+
+// merge adds the bindings of other, replacing those of the same keys.
+func (km Keymap) merge(other Keymap) {
+	for mode, bindings := range other {
+		if km[mode] == nil {
+			km[mode] = map[gruid.Key]playerAction{}
+		}
+		for key, action := range bindings {
+			if action == ActionNone {
+				delete(km[mode], key)
+				continue
+			}
+			km[mode][key] = action
+		}
+	}
+}
+
+// validate reports required actions left without a key, keys both running
+// and bound in normal mode, and inventory menu keys taken by the letters
+// choosing items.
+func (km Keymap) validate() error {
+	var errs []error
+	for _, mode := range keymapModes {
+		for _, action := range requiredActions[mode.Name] {
+			if len(km.keys(mode.Name, action)) == 0 {
+				errs = append(errs, fmt.Errorf("%s: no key is bound to %q", mode.Name, actionInfos[action].Name))
+			}
+		}
+	}
+	for _, key := range sortedKeys(km[keymapRun]) {
+		if action, ok := km[keymapNormal][key]; ok {
+			errs = append(errs, fmt.Errorf("key %q runs %s but is also bound to %q", key,
+				actionInfos[km[keymapRun][key]].Name, actionInfos[action].Name))
+		}
+	}
+	for i := range playerInventorySize {
+		key := inventoryLetter(i)
+		if action, ok := km[keymapInventory][key]; ok {
+			errs = append(errs, fmt.Errorf("key %q chooses an inventory item but is also bound to %q", key,
+				actionInfos[action].Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// action returns the action bound to the key in the given mode, or
+// ActionNone.
+func (km Keymap) action(mode string, key gruid.Key) playerAction {
+	return km[mode][key]
+}
+
+// keys returns the keys bound to the action in the given mode, sorted.
+func (km Keymap) keys(mode string, action playerAction) []gruid.Key {
+	var keys []gruid.Key
+	for _, key := range sortedKeys(km[mode]) {
+		if km[mode][key] == action {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// sortedKeys returns the keys of a mode's bindings, sorted.
+func sortedKeys(bindings map[gruid.Key]playerAction) []gruid.Key {
+	keys := make([]gruid.Key, 0, len(bindings))
+	for key := range bindings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDefaultKeymapHasNoConflicts(t *testing.T) {
+	km, err := ReadKeymap(bytes.NewReader(defaultKeys))
+	if err != nil {
+		t.Fatalf("ReadKeymap(keys.json): %v", err)
+	}
+	if err := km.validate(); err != nil {
+		t.Errorf("keys.json: %v", err)
+	}
+	for _, mode := range keymapModes {
+		if len(km[mode.Name]) == 0 {
+			t.Errorf("keys.json: no bindings for mode %q", mode.Name)
+		}
+	}
+}
+
+func TestKeymapConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string // expected error text, empty for none
+	}{
+		{"valid", `{"normal": {"h": "move-west"}}`, ""},
+		{"bound twice", `{"normal": {"h": "move-west", "h": "rest"}}`, `key "h" is bound more than once`},
+		{"bound twice on a screen", `{"replay": {"p": "pause", "p": "step"}}`, `replay: key "p" is bound more than once`},
+		{"quit unbound", `{"normal": {"q": "none", "Q": "none"}}`, `normal: no key is bound to "quit"`},
+		{"help unbound", `{"normal": {"?": "none"}}`, `normal: no key is bound to "help"`},
+		{"quit moved", `{"normal": {"q": "none", "Q": "none", "X": "quit"}}`, ""},
+		{"menu close unbound", `{"inventory": {"Escape": "none"}}`, `inventory: no key is bound to "close"`},
+		{"runs and bound", `{"normal": {"H": "rest"}}`, `key "H" runs move-west but is also bound to "rest"`},
+		{"unbound run key", `{"run": {"H": "none"}, "normal": {"H": "rest"}}`, ""},
+		{"unknown action", `{"normal": {"h": "fly"}}`, `unknown action "fly"`},
+		{"inventory letter", `{"inventory": {"a": "confirm"}}`, `key "a" chooses an inventory item`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := ReadKeymap(strings.NewReader(tt.data))
+			if err == nil {
+				km := DefaultKeymap()
+				km.merge(user)
+				err = km.validate()
+			}
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
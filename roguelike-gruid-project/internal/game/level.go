@@ -0,0 +1,112 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/sirupsen/logrus"
+)
+
+// Level holds a visited level while the player is on another one, so that it
+// can be restored as it was left.
+type Level struct {
+	Map      *Map // map and explored tiles
+	Entities []LevelEntity
+}
+
+// LevelEntity is an entity resident on a stored level.
+type LevelEntity struct {
+	ID         ecs.EntityID
+	Components map[components.ComponentType]any
+	Scheduled  bool   // whether the entity was in the turn queue
+	Delay      uint64 // time left before its next turn when the level was left
+}
+
+// changeLevel moves the player to the given depth. The current level is
+// stored, and the new one is restored if it was visited before, or generated
+// otherwise. The player arrives on the stairs leading back where they came
+// from.
+func (g *Game) changeLevel(depth int) {
+	from := g.Depth
+	g.levels[from] = g.storeLevel()
+
+	g.Depth = depth
+	g.stats.MaxDepth = max(g.stats.MaxDepth, depth)
+	g.spatialGrid.Clear()
+
+	if level, ok := g.levels[depth]; ok {
+		delete(g.levels, depth)
+		g.restoreLevel(level)
+	} else {
+		g.dungeon = NewMap(config.Config.DungeonWidth, config.Config.DungeonHeight)
+		g.dungeon.generateMap(g)
+	}
+
+	arrival := g.dungeon.StairsUp
+	if depth < from {
+		arrival = g.dungeon.StairsDown
+	}
+	pos := g.freeSpotNear(arrival)
+	g.ecs.AddComponent(g.PlayerID, components.CPosition, pos)
+	g.spatialGrid.Add(g.PlayerID, pos)
+
+	logrus.Infof("Player moved from depth %d to depth %d at %v", from, depth, pos)
+}
+
+// storeLevel removes every entity of the current level but the player from
+// the game, and returns them along with the map.
+func (g *Game) storeLevel() *Level {
+	level := &Level{Map: g.dungeon}
+	for _, id := range g.ecs.GetEntitiesWithComponent(components.CPosition) {
+		if id == g.PlayerID {
+			continue
+		}
+		e := LevelEntity{ID: id, Components: g.ecs.EntityComponents(id)}
+		if t, ok := g.turnQueue.Time(id); ok {
+			e.Scheduled = true
+			e.Delay = t - min(t, g.turnQueue.CurrentTime)
+			g.turnQueue.Remove(id)
+		}
+		g.ecs.RemoveEntity(id)
+		level.Entities = append(level.Entities, e)
+	}
+	return level
+}
+
+// restoreLevel makes a stored level the current one. Actors resume with the
+// delay they had left.
+func (g *Game) restoreLevel(level *Level) {
+	g.dungeon = level.Map
+	for _, e := range level.Entities {
+		g.ecs.RestoreEntity(e.ID, e.Components)
+		if e.Scheduled {
+			g.turnQueue.Add(e.ID, g.turnQueue.CurrentTime+e.Delay)
+		}
+		if _, ok := e.Components[components.CBlocksMovement]; ok {
+			if pos, ok := g.ecs.GetPosition(e.ID); ok {
+				g.spatialGrid.Add(e.ID, pos)
+			}
+		}
+	}
+}
+
+// freeSpotNear returns the walkable position without blocking entities nearest
+// to p, or p itself if there is none.
+func (g *Game) freeSpotNear(p gruid.Point) gruid.Point {
+	for r := 0; r < max(g.dungeon.Width, g.dungeon.Height); r++ {
+		for y := p.Y - r; y <= p.Y+r; y++ {
+			for x := p.X - r; x <= p.X+r; x++ {
+				q := gruid.Point{X: x, Y: y}
+				if paths.DistanceChebyshev(p, q) != r {
+					continue
+				}
+				if g.dungeon.isWalkable(q) && len(g.spatialGrid.GetEntitiesAt(q)) == 0 {
+					return q
+				}
+			}
+		}
+	}
+	return p
+}
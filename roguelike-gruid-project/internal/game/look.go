@@ -0,0 +1,113 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// startLook enters look mode: the targeting cursor without a target to
+// choose, describing what is under it.
+func (md *Model) startLook() {
+	md.startTargeting("Look:", nil)
+	md.targeting.look = true
+}
+
+// describeTile returns what the player knows about a map position: the
+// entities there and the terrain when it is in view, only the remembered
+// terrain when it has been explored but is out of sight.
+func (g *Game) describeTile(p gruid.Point) string {
+	if !g.dungeon.InBounds(p) || !g.dungeon.IsExplored(p) {
+		return "You have not explored there."
+	}
+	terrain := g.dungeon.Describe(g.dungeon.Grid.At(p))
+
+	fov, ok := g.ecs.GetFOV(g.PlayerID)
+	if !ok || !fov.IsVisible(p, g.dungeon.Width) {
+		return fmt.Sprintf("You remember %s there.", terrain)
+	}
+
+	var seen []string
+	for _, id := range g.ecs.EntitiesAt(p) {
+		if desc := g.describeEntity(id); desc != "" {
+			seen = append(seen, desc)
+		}
+	}
+	if len(seen) == 0 {
+		return fmt.Sprintf("You see %s.", terrain)
+	}
+	return fmt.Sprintf("On %s: %s.", terrain, strings.Join(seen, "; "))
+}
+
+// describeEntity returns a description of a visible entity, such as "a
+// wounded Troll, asleep".
+func (g *Game) describeEntity(id ecs.EntityID) string {
+	if id == g.PlayerID {
+		return "yourself"
+	}
+	name, ok := g.ecs.GetName(id)
+	if !ok {
+		return ""
+	}
+	if g.ecs.HasComponent(id, components.CCorpseTag) {
+		return withArticle(name + " corpse")
+	}
+	if !g.ecs.HasComponent(id, components.CAITag) {
+		return withArticle(name)
+	}
+
+	desc := name
+	if health, ok := g.ecs.GetHealth(id); ok {
+		if wound := woundDescription(health); wound != "" {
+			desc = wound + " " + desc
+		}
+	}
+	desc = withArticle(desc)
+	if state, ok := g.ecs.GetAIState(id); ok {
+		desc += ", " + state.String()
+	}
+	if g.ecs.HasComponent(id, components.CConfused) {
+		desc += ", confused"
+	}
+	return desc
+}
+
+// woundDescription returns how hurt an entity with the given health looks,
+// or an empty string if it is unhurt.
+func woundDescription(h components.Health) string {
+	switch {
+	case h.CurrentHP >= h.MaxHP:
+		return ""
+	case h.CurrentHP*2 > h.MaxHP:
+		return "lightly wounded"
+	case h.CurrentHP*4 > h.MaxHP:
+		return "wounded"
+	}
+	return "badly wounded"
+}
+
+// withArticle prefixes a noun phrase with the matching indefinite article.
+func withArticle(s string) string {
+	if s != "" && strings.ContainsRune("aeiouAEIOU", rune(s[0])) {
+		return "an " + s
+	}
+	return "a " + s
+}
+
+// drawHover describes the explored tile under the mouse in place of the
+// visible monsters list.
+func (md *Model) drawHover() {
+	g := md.game
+	rg := md.hud.Range()
+	if rg.Size().Y < 2 || !g.dungeon.InBounds(md.hover) || !g.dungeon.IsExplored(md.hover) {
+		return
+	}
+	md.drawTravelHover()
+	line := md.hud.Slice(rg.Line(1))
+	line.Fill(gruid.Cell{Rune: ' '})
+	drawText(line, 0, g.describeTile(md.hover), gruid.Style{Fg: ui.ColorUIText})
+}
@@ -1,27 +1,19 @@
 package game
 
 import (
-	"math/rand"
-	"slices"
-
 	"codeberg.org/anaseto/gruid"
 	"codeberg.org/anaseto/gruid/rl" // Use rl package which contains FOV
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
 	"github.com/sirupsen/logrus"
 )
 
-// Game settings & map generation constants
-const (
-	maxRooms           = 10
-	roomMinSize        = 6
-	roomMaxSize        = 10
-	maxMonstersPerRoom = 2 // Max monsters per room (excluding first)
-)
-
 // TileType represents the type of a map tile.
 
 const (
 	WallCell rl.Cell = iota
 	FloorCell
+	StairsDownCell
+	StairsUpCell
 )
 
 // Map represents the game map's logical state and visibility.
@@ -30,6 +22,10 @@ type Map struct {
 	Width    int
 	Height   int
 	Explored []uint64 // Bitset for explored tiles (Global map knowledge)
+
+	StairsDown gruid.Point // Stairs leading to the next level
+	StairsUp   gruid.Point // Stairs leading to the previous level, if any
+	Regions    []Region    // Rooms and cave parts, used by wandering monsters
 }
 
 // NewMap creates a new map initialized with walls and visibility data.
@@ -44,65 +40,72 @@ func NewMap(width, height int) *Map {
 	return m
 }
 
-// generateMap creates a new map layout with rooms and tunnels, and spawns monsters.
-// It now takes the game struct to access ECS and TurnQueue.
-func (m *Map) generateMap(g *Game, width, height int) gruid.Point {
+// generateMap creates a new map layout with the generator chosen for the
+// current depth, and spawns monsters and items in its regions. Stairs down are
+// placed in the last region, and stairs up at the player start below depth 1.
+// Vaults are stamped before spawning, so that regions exclude them.
+func (m *Map) generateMap(g *Game) gruid.Point {
 	m.Grid.Fill(WallCell)
 
-	var rooms []Rect
-	var playerStart gruid.Point = gruid.Point{X: 0, Y: 0}
-
-	for range maxRooms {
-		w := rand.Intn(roomMaxSize-roomMinSize+1) + roomMinSize
-		h := rand.Intn(roomMaxSize-roomMinSize+1) + roomMinSize
-		x := rand.Intn(width - w - 1)  // -1 to ensure room fits
-		y := rand.Intn(height - h - 1) // -1 to ensure room fits
-
-		newRoom := NewRect(x, y, w, h)
-
-		// Check for intersections with existing rooms
-		intersects := slices.ContainsFunc(rooms, newRoom.Intersects)
-
-		if !intersects {
-			createRoom(m.Grid, newRoom)
-			newCenter := newRoom.Center()
-
-			if len(rooms) == 0 {
-				// This is the first room, place player here
-				playerStart = newCenter
-			} else {
-				// Connect to the previous room's center
-				prevCenter := rooms[len(rooms)-1].Center()
-
-				// Randomly decide tunnel order (H then V or V then H)
-				if rand.Intn(2) == 0 {
-					createHTunnel(m.Grid, prevCenter.X, newCenter.X, prevCenter.Y)
-					createVTunnel(m.Grid, prevCenter.Y, newCenter.Y, newCenter.X)
-				} else {
-					createVTunnel(m.Grid, prevCenter.Y, newCenter.Y, prevCenter.X)
-					createHTunnel(m.Grid, prevCenter.X, newCenter.X, newCenter.Y)
-				}
-				// Spawn monsters in this room (if not the first room)
-				m.placeMonsters(g, newRoom)
-			}
-			rooms = append(rooms, newRoom)
+	name := generatorFor(g.Depth)
+	logrus.Debugf("Generating depth %d with the %s generator", g.Depth, name)
+	regions := generators[name].Generate(m, g.rand, config.Config)
+	playerStart := regions[0].Center()
+	m.placeStairs(g, regions, playerStart)
+	regions, vaultRegions := m.placeVaults(g, regions, playerStart)
+	for i, region := range regions {
+		if i > 0 {
+			// No monsters in the player's starting region
+			m.placeMonsters(g, region)
 		}
+		m.placeItems(g, region)
 	}
 
+	m.Regions = append(regions, vaultRegions...)
+
 	return playerStart
 }
 
+// placeStairs places the stairs of a level generated at the current depth.
+func (m *Map) placeStairs(g *Game, regions []Region, playerStart gruid.Point) {
+	last := regions[len(regions)-1]
+	m.StairsDown = last.Center()
+	if m.StairsDown == playerStart {
+		// Single region: use its last position instead of its center
+		m.StairsDown = last[len(last)-1]
+	}
+	m.Grid.Set(m.StairsDown, StairsDownCell)
+
+	if g.Depth > 1 {
+		m.StairsUp = playerStart
+		m.Grid.Set(m.StairsUp, StairsUpCell)
+	}
+}
+
 // InBounds checks if coordinates are within map bounds.
 func (m *Map) InBounds(p gruid.Point) bool {
 	return p.X >= 0 && p.X < m.Width && p.Y >= 0 && p.Y < m.Height
 }
 
-// isWalkable checks if a tile is a floor tile.
+// isWalkable checks if a tile is a floor or stairs tile.
 func (m *Map) isWalkable(p gruid.Point) bool {
 	if !m.InBounds(p) {
 		return false
 	}
-	return m.Grid.At(p) == FloorCell
+	switch m.Grid.At(p) {
+	case FloorCell, StairsDownCell, StairsUpCell:
+		return true
+	}
+	return false
+}
+
+// IsStairs checks if the tile at the given point is a staircase.
+func (m *Map) IsStairs(p gruid.Point) bool {
+	if !m.InBounds(p) {
+		return false
+	}
+	c := m.Grid.At(p)
+	return c == StairsDownCell || c == StairsUpCell
 }
 
 // IsWall checks if the tile at the given point is a wall.
@@ -167,22 +170,40 @@ func (m *Map) Rune(c rl.Cell) (r rune) {
 		r = '#'
 	case FloorCell:
 		r = '.'
+	case StairsDownCell:
+		r = '>'
+	case StairsUpCell:
+		r = '<'
 	}
 	return r
 }
 
-// placeMonsters spawns monsters in a given room.
-func (m *Map) placeMonsters(g *Game, room Rect) {
-	// Determine number of monsters for this room (e.g., 0 to maxMonstersPerRoom)
-	numMonsters := rand.Intn(maxMonstersPerRoom + 1) // +1 because Intn is exclusive upper bound
-	logrus.Debugf("Placing %d monsters in room: %v", numMonsters, room)
+// Describe returns the name of a map cell type, as shown by the look command.
+func (m *Map) Describe(c rl.Cell) string {
+	switch c {
+	case WallCell:
+		return "a wall"
+	case FloorCell:
+		return "the floor"
+	case StairsDownCell:
+		return "stairs leading down"
+	case StairsUpCell:
+		return "stairs leading up"
+	}
+	return "something strange"
+}
+
+// placeMonsters spawns monsters in a given region.
+func (m *Map) placeMonsters(g *Game, region Region) {
+	// Determine number of monsters for this room (e.g., 0 to MaxMonstersPerRoom),
+	// with one more allowed every other level
+	maxMonsters := config.Config.MaxMonstersPerRoom + (g.Depth-1)/2
+	numMonsters := g.rand.Intn(maxMonsters + 1) // +1 because Intn is exclusive upper bound
+	logrus.Debugf("Placing %d monsters in a region of %d tiles", numMonsters, len(region))
 
 	for i := 0; i < numMonsters; i++ {
-		// Find a random walkable tile within the room bounds
-		// Add +1 to x1, y1 and -1 to x2, y2 to avoid spawning on walls
-		x := rand.Intn(room.X2-room.X1-1) + room.X1 + 1
-		y := rand.Intn(room.Y2-room.Y1-1) + room.Y1 + 1
-		pos := gruid.Point{X: x, Y: y}
+		// Pick a random tile of the region
+		pos := region[g.rand.Intn(len(region))]
 
 		// Check if the tile is walkable and not already occupied
 		if m.isWalkable(pos) && len(g.ecs.EntitiesAt(pos)) == 0 {
@@ -194,4 +215,18 @@ func (m *Map) placeMonsters(g *Game, room Rect) {
 	}
 }
 
-// Constants like maxRooms, roomMinSize, roomMaxSize, maxMonstersPerRoom should be defined centrally (e.g., in game.go)
+// placeItems spawns items in a given region.
+func (m *Map) placeItems(g *Game, region Region) {
+	numItems := g.rand.Intn(config.Config.MaxItemsPerRoom + 1)
+	logrus.Debugf("Placing %d items in a region of %d tiles", numItems, len(region))
+
+	for i := 0; i < numItems; i++ {
+		pos := region[g.rand.Intn(len(region))]
+
+		if m.isWalkable(pos) && len(g.ecs.EntitiesAt(pos)) == 0 {
+			g.SpawnItem(pos)
+		} else {
+			logrus.Debugf("Failed to spawn item at position %v - not walkable or occupied", pos)
+		}
+	}
+}
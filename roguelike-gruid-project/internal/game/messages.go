@@ -0,0 +1,166 @@
+package game
+
+import (
+	"fmt"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/log"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// messageHistory holds the state of the full-screen message history pager.
+type messageHistory struct {
+	pager   *gui.Pager
+	input   *gui.TextInput // non-nil while a search query is being typed
+	status  gruid.Grid     // bottom line for search prompt and help
+	query   string
+	matches []int // indices of messages matching query
+	current int   // index in matches of the focused match
+}
+
+// messageStyle returns the style used to draw a message. Messages that arrived
+// during the current turn are emphasized, critical ones stand out even more.
+func messageStyle(m log.Message) gruid.Style {
+	st := gruid.Style{Fg: m.Color}
+	if m.Tick {
+		st.Attrs |= ui.AttrBold
+	}
+	if m.IsCritical() {
+		st.Attrs |= ui.AttrReverse
+	}
+	return st
+}
+
+// drawLogPanel draws the most recent messages in the panel below the map.
+func (md *Model) drawLogPanel() {
+	md.logPanel.Fill(gruid.Cell{Rune: ' '})
+
+	rg := md.logPanel.Range()
+	msgs := md.game.log.GetMessages(rg.Size().Y)
+	for i, m := range msgs {
+		line := md.logPanel.Slice(rg.Line(i))
+		gui.NewStyledText(m.String(), messageStyle(m)).Draw(line)
+	}
+}
+
+// openMessageHistory switches to the message history pager, scrolled to the
+// latest messages.
+func (md *Model) openMessageHistory() {
+	rg := md.grid.Range()
+	h := rg.Size().Y
+	md.msgHistory = &messageHistory{
+		pager: gui.NewPager(gui.PagerConfig{
+			Grid: md.grid.Slice(rg.Lines(0, h-1)),
+			Box: &gui.Box{
+				Style: gruid.Style{Fg: ui.ColorUIBorder},
+				Title: gui.NewStyledText("Message History", gruid.Style{Fg: ui.ColorUITitle}),
+			},
+			Keys: gui.PagerKeys{Quit: append(keymap.keys(keymapMessages, ActionClose), keymap.keys(keymapNormal, ActionMessageLog)...)},
+		}),
+		status: md.grid.Slice(rg.Line(h - 1)),
+	}
+	md.msgHistory.setLines(md.game.log)
+	md.msgHistory.pager.SetCursor(gruid.Point{Y: len(md.game.log.Messages)})
+	md.grid.Fill(gruid.Cell{Rune: ' '})
+	md.mode = modeMessageLog
+}
+
+// updateMessageHistory handles input while the message history is shown.
+func (md *Model) updateMessageHistory(msg gruid.Msg) gruid.Effect {
+	mh := md.msgHistory
+
+	if mh.input != nil {
+		mh.input.Update(msg)
+		switch mh.input.Action() {
+		case gui.TextInputInvoke:
+			mh.search(md.game.log, mh.input.Content())
+			mh.input = nil
+		case gui.TextInputQuit:
+			mh.input = nil
+		}
+		return nil
+	}
+
+	if key, ok := msg.(gruid.MsgKeyDown); ok {
+		switch keymap.action(keymapMessages, key.Key) {
+		case ActionSearch:
+			mh.input = gui.NewTextInput(gui.TextInputConfig{
+				Grid:   mh.status,
+				Prompt: gui.NewStyledText("Search: ", gruid.Style{Fg: ui.ColorUIHighlight}),
+			})
+			return nil
+		case ActionNextMatch:
+			mh.jump(1)
+			return nil
+		case ActionPrevMatch:
+			mh.jump(-1)
+			return nil
+		}
+	}
+
+	mh.pager.Update(msg)
+	if mh.pager.Action() == gui.PagerQuit {
+		md.msgHistory = nil
+		md.mode = modeNormal
+	}
+	return nil
+}
+
+// drawMessageHistory draws the message history pager and its status line.
+func (md *Model) drawMessageHistory() {
+	mh := md.msgHistory
+	mh.pager.Draw()
+
+	if mh.input != nil {
+		mh.input.Draw()
+		return
+	}
+
+	mh.status.Fill(gruid.Cell{Rune: ' '})
+	text := joinHints(
+		keyHint(keymapMessages, ActionSearch, "search"),
+		keyHint(keymapMessages, ActionNextMatch, "next"),
+		keyHint(keymapMessages, ActionPrevMatch, "previous"),
+		keyHint(keymapMessages, ActionClose, "close"))
+	if mh.query != "" {
+		text = fmt.Sprintf("'%s': %d matches  %s", mh.query, len(mh.matches), text)
+	}
+	gui.NewStyledText(text, gruid.Style{Fg: ui.ColorUIText}).Draw(mh.status)
+}
+
+// setLines rebuilds the pager lines from the log, highlighting search matches.
+func (mh *messageHistory) setLines(ml *log.MessageLog) {
+	matched := make(map[int]bool, len(mh.matches))
+	for _, i := range mh.matches {
+		matched[i] = true
+	}
+
+	lines := make([]gui.StyledText, 0, len(ml.Messages))
+	for i, m := range ml.Messages {
+		st := messageStyle(m)
+		if matched[i] {
+			st = st.WithFg(ui.ColorUIHighlight).WithAttrs(st.Attrs | ui.AttrReverse)
+		}
+		lines = append(lines, gui.NewStyledText(m.String(), st))
+	}
+	mh.pager.SetLines(lines)
+}
+
+// search highlights the messages matching query and scrolls to the latest one.
+func (mh *messageHistory) search(ml *log.MessageLog, query string) {
+	mh.query = query
+	mh.matches = ml.Search(query)
+	mh.current = len(mh.matches)
+	mh.setLines(ml)
+	mh.jump(-1)
+}
+
+// jump scrolls the pager to the next (dir > 0) or previous (dir < 0) match.
+func (mh *messageHistory) jump(dir int) {
+	if len(mh.matches) == 0 {
+		return
+	}
+	mh.current = (mh.current + dir + len(mh.matches)) % len(mh.matches)
+	mh.pager.SetCursor(gruid.Point{Y: mh.matches[mh.current]})
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -18,6 +20,12 @@ type mode int
 const (
 	modeNormal mode = iota
 	modeQuit
+	modeMessageLog
+	modeGameOver
+	modeInventory
+	modeCharacter
+	modeTargeting
+	modeHelp
 )
 
 // Model represents the game model that implements gruid.Model
@@ -26,23 +34,68 @@ type Model struct {
 	game *Game
 	mode mode
 
+	// Grid slices for the different screen areas
+	viewport gruid.Grid // map and entities
+	hud      gruid.Grid // player status and visible monsters
+	logPanel gruid.Grid // latest messages
+
+	msgHistory *messageHistory // full-screen message history pager
+	inventory  *inventoryMenu  // inventory menu, drawn over the map
+	targeting  *targeting      // target selection, in targeting mode
+	help       *gui.Pager      // full-screen key bindings help
+
+	hover    gruid.Point // map position under the mouse
+	hovering bool        // whether the mouse is over the map
+
+	savePath   string     // save file location, empty if saving is disabled
+	recording  *Recording // input recorded since launch, nil if not recording
+	recordPath string     // replay file location, empty if not recording
+	ended      bool       // whether the model asked the application to end
+
 	// Debug information
 	lastUpdateTime time.Time
 	updateCount    uint64
 	lastEffect     gruid.Effect
 }
 
-// NewModel creates a new game model
-func NewModel(grid gruid.Grid) *Model {
+// NewModel creates a new game model, starting a run with the given seed unless
+// a saved game is resumed.
+func NewModel(grid gruid.Grid, seed int64) *Model {
+	cfg := config.Config
 	return &Model{
 		grid:           grid,
-		game:           NewGame(),
+		game:           NewGame(seed),
 		mode:           modeNormal,
+		viewport:       grid.Slice(gruid.NewRange(0, 0, cfg.DungeonWidth, cfg.DungeonHeight)),
+		hud:            grid.Slice(gruid.NewRange(0, cfg.DungeonHeight, cfg.UIWidth(), cfg.DungeonHeight+config.StatusHeight)),
+		logPanel:       grid.Slice(gruid.NewRange(0, cfg.DungeonHeight+config.StatusHeight, cfg.UIWidth(), cfg.UIHeight())),
 		lastUpdateTime: time.Now(),
 	}
 }
 
 func (md *Model) init() gruid.Effect {
+	if md.recordPath != "" {
+		md.recording = &Recording{
+			Version: recordingVersion,
+			Seed:    md.game.Seed(),
+			Rules:   config.Config.Rules,
+			Data:    currentDataDigests(),
+		}
+	}
+	if !md.loadSavedGame() {
+		md.startGame()
+	}
+
+	if runtime.GOOS == "js" {
+		return nil
+	}
+
+	return gruid.Sub(utils.HandleSignals)
+}
+
+// startGame initializes the first level of the current game and runs turns
+// until the player can act.
+func (md *Model) startGame() {
 	logrus.Debug("========= Game Initialization Started =========")
 	md.game.InitLevel()
 
@@ -54,12 +107,29 @@ func (md *Model) init() gruid.Effect {
 
 	logrus.Debug("Initial turn queue processing completed")
 	logrus.Debug("========= Game Initialization Completed =========")
+}
 
-	if runtime.GOOS == "js" {
-		return nil
-	}
+// AdvanceTurns runs turns until the player has to act, as happens after each
+// player action.
+func (md *Model) AdvanceTurns() {
+	md.processTurnQueue()
+	md.checkGameOver()
+}
 
-	return gruid.Sub(utils.HandleSignals)
+// Game returns the current game.
+func (md *Model) Game() *Game {
+	return md.game
+}
+
+// end asks the application to end.
+func (md *Model) end() gruid.Effect {
+	md.ended = true
+	return gruid.End()
+}
+
+// Ended reports whether the model asked the application to end.
+func (md *Model) Ended() bool {
+	return md.ended
 }
 
 // EndTurn finalizes player's turn and runs other events until next player
@@ -70,9 +140,11 @@ func (md *Model) EndTurn() gruid.Effect {
 	md.mode = modeNormal
 	g := md.game
 	g.waitingForInput = false
+	g.log.NewTurn()
+	g.noise = noise{}
 
-	g.monstersTurn()
 	md.processTurnQueue()
+	md.checkGameOver()
 
 	// Track update metrics
 	md.updateCount++
@@ -92,5 +164,6 @@ func (md *Model) GetDebugInfo() map[string]any {
 		"waitingForInput": md.game.waitingForInput,
 		"turnQueueSize":   md.game.turnQueue.Len(),
 		"currentTime":     md.game.turnQueue.CurrentTime,
+		"monsterStates":   md.game.monsterStates(),
 	}
 }
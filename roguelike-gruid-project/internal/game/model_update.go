@@ -23,9 +23,18 @@ func (md *Model) Update(msg gruid.Msg) gruid.Effect {
 		return md.init()
 	}
 
-	// Handle quit command
-	if key, ok := msg.(gruid.MsgKeyDown); ok && key.Key == "q" {
-		return gruid.End()
+	md.record(msg)
+
+	// Handle quit command, saving the game first
+	if key, ok := msg.(gruid.MsgKeyDown); ok && md.mode == modeNormal && keymap.action(keymapNormal, key.Key) == ActionQuit {
+		md.saveGame()
+		md.writeRecording()
+		return md.end()
+	}
+	if _, ok := msg.(gruid.MsgQuit); ok {
+		md.saveGame()
+		md.writeRecording()
+		return md.end()
 	}
 
 	return md.processGameUpdate(msg)
@@ -68,6 +77,11 @@ func (md *Model) processGameUpdate(msg gruid.Msg) gruid.Effect {
 
 	g := md.game
 
+	// Once the player is dead, only the death screen receives input
+	if md.mode == modeGameOver {
+		return md.updateGameOver(msg)
+	}
+
 	// Log the current game state for debugging
 	logrus.WithFields(logrus.Fields{
 		"waitingForInput": g.waitingForInput,
@@ -105,6 +119,16 @@ func (md *Model) handlePlayerInput(msg gruid.Msg) gruid.Effect {
 		return nil
 	case modeNormal:
 		effect = md.processNormalModeInput(msg)
+	case modeMessageLog:
+		effect = md.updateMessageHistory(msg)
+	case modeInventory:
+		effect = md.updateInventory(msg)
+	case modeCharacter:
+		effect = md.updateCharacter(msg)
+	case modeTargeting:
+		effect = md.updateTargeting(msg)
+	case modeHelp:
+		effect = md.updateHelp(msg)
 	default:
 		logrus.Warnf("Unexpected game mode: %v", md.mode)
 		return nil
@@ -140,9 +164,14 @@ func (md *Model) handleKeyDown(msg gruid.MsgKeyDown) gruid.Effect {
 	return md.EndTurn()
 }
 
-// handleMouse processes mouse input
+// handleMouse processes mouse input: hovering over the map describes the
+// tile under the mouse, and clicking an explored tile travels there.
 func (md *Model) handleMouse(msg gruid.MsgMouse) gruid.Effect {
-	// TODO: Implement mouse handling if needed
+	md.hovering = msg.P.In(md.viewport.Bounds())
+	md.hover = msg.P.Sub(md.viewport.Bounds().Min)
+	if msg.Action == gruid.MouseMain && md.hovering {
+		return md.travelTo(md.hover)
+	}
 	return nil
 }
 
@@ -152,6 +181,7 @@ func (md *Model) processTurnQueueWithEffect() gruid.Effect {
 
 	// Process the turn queue
 	md.processTurnQueue()
+	md.checkGameOver()
 
 	// Return nil to trigger a redraw
 	// This ensures the screen updates after monster moves
@@ -160,10 +190,19 @@ func (md *Model) processTurnQueueWithEffect() gruid.Effect {
 
 // normalModeKeyDown processes a key press in normal mode
 func (md *Model) normalModeKeyDown(key gruid.Key, shift bool) (again bool, effect gruid.Effect, err error) {
-	action := KEYS_NORMAL[key]
+	action := keymap.action(keymapNormal, key)
+	if run := keymap.action(keymapRun, key); run != ActionNone {
+		action, shift = run, true
+	}
+	if dir := keyToDir(action); shift && dir != (gruid.Point{}) {
+		return md.run(dir)
+	}
 	again, effect, err = md.normalModeAction(action)
 	if _, ok := err.(actionError); ok {
-		err = fmt.Errorf("key '%s' does nothing. Type ? for help", key)
+		err = fmt.Errorf("key '%s' does nothing", key)
+		if keys := keymap.keys(keymapNormal, ActionHelp); len(keys) > 0 {
+			err = fmt.Errorf("%w. Type %s for help", err, keyName(keys[0]))
+		}
 	}
 	return again, effect, err
 }
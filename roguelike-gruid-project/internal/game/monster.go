@@ -2,66 +2,299 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
 
 	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
 	"github.com/sirupsen/logrus"
 )
 
-// monstersTurn handles AI turns for all monsters in the game.
-func (g *Game) monstersTurn() {
-	aiEntities := g.ecs.GetEntitiesWithComponent(components.CAITag)
-	for _, id := range aiEntities {
+// blockedStepCost is the path cost of a tile occupied by a blocking entity:
+// monsters prefer going around each other over waiting in line.
+const blockedStepCost = 8
 
-		actor, ok := g.ecs.GetTurnActor(id)
-		if !ok {
-			continue
+// fleeHealthPercent is the health percentage below which monsters flee from
+// the player.
+const fleeHealthPercent = 25
+
+// monsterAction decides the next action of an AI-controlled entity, when its
+// turn comes, according to its AI state:
+//
+//   - asleep monsters wait until woken up by noise or the player next to them;
+//   - hunting monsters path toward the player while they see them, attack by
+//     bumping into them, and head for where they last saw them otherwise;
+//   - fleeing monsters, badly hurt or cowardly, run away from the player;
+//   - wandering monsters, without a target, patrol between rooms or roam.
+func (g *Game) monsterAction(id ecs.EntityID) GameAction {
+	pos, ok := g.ecs.GetPosition(id)
+	if !ok {
+		return WaitAction{EntityID: id}
+	}
+	if confused, ok := g.ecs.GetConfused(id); ok {
+		return g.confusedAction(id, pos, confused)
+	}
+	memory, _ := g.ecs.GetAIMemory(id)
+	state, _ := g.ecs.GetAIState(id)
+	defer func() {
+		g.ecs.AddComponents(id, memory, state)
+	}()
+
+	if state == components.AIAsleep {
+		if !g.wakesUp(pos) {
+			return WaitAction{EntityID: id}
 		}
+		logrus.Debugf("AI entity %d wakes up", id)
+		state = components.AIWandering
+	}
 
-		if !actor.IsAlive() {
-			continue
+	playerPos, _ := g.ecs.GetPosition(g.PlayerID)
+	seesPlayer := g.canSee(id, playerPos)
+	if seesPlayer {
+		memory.LastKnownTarget = playerPos
+		memory.Tracking = true
+	}
+
+	switch {
+	case g.wantsToFlee(id) && seesPlayer:
+		state = components.AIFleeing
+	case g.wantsToFlee(id):
+		memory.Tracking = false
+		state = components.AIWandering
+	case memory.Tracking:
+		state = components.AIHunting
+	default:
+		state = components.AIWandering
+	}
+
+	switch state {
+	case components.AIFleeing:
+		return g.flee(id, pos, playerPos)
+	case components.AIHunting:
+		if action, ok := g.moveToward(id, pos, memory.LastKnownTarget); ok {
+			return action
 		}
+		// Reached the last known position, or no way there: give up
+		logrus.Debugf("AI entity %d lost track of its target", id)
+		memory.Tracking = false
+		state = components.AIWandering
+	}
+	return g.wander(id, pos, &memory)
+}
 
-		if actor.PeekNextAction() != nil {
+// wakesUp reports whether a sleeping monster at pos wakes up. It always does
+// next to the player, and may otherwise be woken up by the noise made during
+// the turn, more likely the closer it is.
+func (g *Game) wakesUp(pos gruid.Point) bool {
+	if playerPos, ok := g.ecs.GetPosition(g.PlayerID); ok && paths.DistanceChebyshev(pos, playerPos) <= 1 {
+		return true
+	}
+	d := paths.DistanceChebyshev(pos, g.noise.Pos)
+	if g.noise.Radius == 0 || d > g.noise.Radius {
+		return false
+	}
+	return g.rand.Intn(g.noise.Radius+1) >= d
+}
+
+// wantsToFlee reports whether the monster would rather avoid the player:
+// cowards always do, others when badly hurt.
+func (g *Game) wantsToFlee(id ecs.EntityID) bool {
+	if tag, ok := g.ecs.GetAITag(id); ok && tag.Behavior == components.BehaviorCoward {
+		return true
+	}
+	health, ok := g.ecs.GetHealth(id)
+	return ok && health.CurrentHP*100 <= health.MaxHP*fleeHealthPercent
+}
+
+// alertMonster wakes up a monster attacked from the given position, and makes
+// it go after its attacker.
+func (g *Game) alertMonster(id ecs.EntityID, from gruid.Point) {
+	state, ok := g.ecs.GetAIState(id)
+	if !ok {
+		return
+	}
+	memory, _ := g.ecs.GetAIMemory(id)
+	memory.LastKnownTarget = from
+	memory.Tracking = true
+	if state == components.AIAsleep {
+		state = components.AIWandering
+	}
+	g.ecs.AddComponents(id, memory, state)
+}
+
+// flee returns an action taking the monster away from the player. A cornered
+// monster fights back.
+func (g *Game) flee(id ecs.EntityID, pos, playerPos gruid.Point) GameAction {
+	best, bestDist := pos, paths.DistanceChebyshev(pos, playerPos)
+	nb := paths.Neighbors{}
+	for _, q := range nb.All(pos, g.dungeon.isWalkable) {
+		if len(g.spatialGrid.GetEntitiesAt(q)) > 0 {
 			continue
 		}
+		if d := paths.DistanceChebyshev(q, playerPos); d > bestDist {
+			best, bestDist = q, d
+		}
+	}
+	if best != pos {
+		logrus.Debugf("AI entity %d flees to %v", id, best)
+		return MoveAction{Direction: best.Sub(pos), EntityID: id}
+	}
+	if paths.DistanceChebyshev(pos, playerPos) == 1 {
+		return MoveAction{Direction: playerPos.Sub(pos), EntityID: id}
+	}
+	return WaitAction{EntityID: id}
+}
 
-		moveOrWait := rand.Intn(2)
-		if moveOrWait == 0 {
-			action, err := moveMonster(g, id)
-			if err != nil {
-				logrus.Debugf("Failed to move monster %d: %v", id, err)
-				continue
+// wander returns an action for a monster without target. Wanderers patrol
+// between rooms, while other monsters roam around where they are.
+func (g *Game) wander(id ecs.EntityID, pos gruid.Point, memory *components.AIMemory) GameAction {
+	if tag, _ := g.ecs.GetAITag(id); tag.Behavior == components.BehaviorWanderer {
+		if !memory.Patrolling || pos == memory.PatrolGoal {
+			memory.PatrolGoal, memory.Patrolling = g.patrolGoal(pos)
+		}
+		if memory.Patrolling {
+			if action, ok := g.moveToward(id, pos, memory.PatrolGoal); ok {
+				return action
 			}
-			actor.AddAction(action)
-		} else {
-			actor.AddAction(WaitAction{EntityID: id})
+			memory.Patrolling = false
+		}
+	}
+
+	action, err := moveMonster(g, id)
+	if err != nil {
+		logrus.Debugf("Failed to move monster %d: %v", id, err)
+		return WaitAction{EntityID: id}
+	}
+	return action
+}
+
+// patrolGoal picks the center of a random region other than the one at pos.
+// It returns false if there is no such region.
+func (g *Game) patrolGoal(pos gruid.Point) (gruid.Point, bool) {
+	regions := g.dungeon.Regions
+	if len(regions) == 0 {
+		return gruid.Point{}, false
+	}
+	goal := regions[g.rand.Intn(len(regions))].Center()
+	return goal, goal != pos
+}
+
+// monsterStates returns the AI state of every monster on the level.
+func (g *Game) monsterStates() map[ecs.EntityID]string {
+	states := make(map[ecs.EntityID]string)
+	for _, id := range g.ecs.GetEntitiesWithComponent(components.CAIState) {
+		state, _ := g.ecs.GetAIState(id)
+		states[id] = state.String()
+	}
+	return states
+}
+
+// describeMonster returns the name of a monster along with its AI state and
+// whether it is confused.
+func (g *Game) describeMonster(id ecs.EntityID) string {
+	name, _ := g.ecs.GetName(id)
+	state, ok := g.ecs.GetAIState(id)
+	if !ok {
+		return name
+	}
+	if g.ecs.HasComponent(id, components.CConfused) {
+		return fmt.Sprintf("%s (%s, confused)", name, state)
+	}
+	return fmt.Sprintf("%s (%s)", name, state)
+}
+
+// canSee reports whether the entity currently sees the given position.
+func (g *Game) canSee(id ecs.EntityID, p gruid.Point) bool {
+	fov, ok := g.ecs.GetFOV(id)
+	return ok && fov.IsVisible(p, g.dungeon.Width)
+}
+
+// moveToward returns an action taking the entity one step along a path to the
+// target. It returns false if the entity is already there or there is no path.
+func (g *Game) moveToward(id ecs.EntityID, from, to gruid.Point) (GameAction, bool) {
+	if from == to {
+		return nil, false
+	}
+	path := g.pathRange().AstarPath(&aiPather{g: g}, from, to)
+	if len(path) < 2 {
+		return nil, false
+	}
+
+	next := path[1]
+	for _, other := range g.spatialGrid.GetEntitiesAt(next) {
+		if other != g.PlayerID {
+			// Another monster is in the way: let it move first
+			return WaitAction{EntityID: id}, true
 		}
 	}
+	logrus.Debugf("AI entity %d moving toward %v", id, to)
+	return MoveAction{Direction: next.Sub(from), EntityID: id}, true
+}
+
+// pathRange returns the path range used for pathfinding on the current map.
+func (g *Game) pathRange() *paths.PathRange {
+	rg := gruid.NewRange(0, 0, g.dungeon.Width, g.dungeon.Height)
+	if g.paths == nil || g.paths.Range() != rg {
+		g.paths = paths.NewPathRange(rg)
+	}
+	return g.paths
+}
+
+// aiPather implements paths.Astar for monster movement in the eight
+// directions, like the player.
+type aiPather struct {
+	g  *Game
+	nb paths.Neighbors
+}
+
+// Neighbors implements paths.Pather.Neighbors.
+func (ap *aiPather) Neighbors(p gruid.Point) []gruid.Point {
+	return ap.nb.All(p, ap.g.dungeon.isWalkable)
 }
 
+// Cost implements paths.Dijkstra.Cost.
+func (ap *aiPather) Cost(from, to gruid.Point) int {
+	if len(ap.g.spatialGrid.GetEntitiesAt(to)) > 0 {
+		return blockedStepCost
+	}
+	return 1
+}
+
+// Estimation implements paths.Astar.Estimation.
+func (ap *aiPather) Estimation(from, to gruid.Point) int {
+	return paths.DistanceChebyshev(from, to)
+}
+
+// moveMonster returns a wandering action: a random step to a free tile, or a
+// wait.
 func moveMonster(g *Game, id ecs.EntityID) (GameAction, error) {
 	pos, ok := g.ecs.GetPosition(id)
 	if !ok {
 		return nil, fmt.Errorf("entity %d has no position", id)
 	}
 
+	if g.rand.Intn(2) == 1 {
+		return WaitAction{EntityID: id}, nil
+	}
+
 	directions := []gruid.Point{
-		{X: -1, Y: 0}, // West
-		{X: 1, Y: 0},  // East
-		{X: 0, Y: -1}, // North
-		{X: 0, Y: 1},  // South
+		{X: -1, Y: 0},  // West
+		{X: 1, Y: 0},   // East
+		{X: 0, Y: -1},  // North
+		{X: 0, Y: 1},   // South
+		{X: -1, Y: -1}, // North-west
+		{X: 1, Y: -1},  // North-east
+		{X: -1, Y: 1},  // South-west
+		{X: 1, Y: 1},   // South-east
 	}
 	// This is a simple way to randomize the order of directions
-	rand.Shuffle(len(directions), func(i, j int) {
+	g.rand.Shuffle(len(directions), func(i, j int) {
 		directions[i], directions[j] = directions[j], directions[i]
 	})
 	var validMove *gruid.Point
 	for _, dir := range directions {
 		newPos := pos.Add(dir)
-		if g.dungeon.isWalkable(newPos) && len(g.ecs.EntitiesAt(newPos)) == 0 { // EntitiesAt already uses new system
+		if g.dungeon.isWalkable(newPos) && len(g.spatialGrid.GetEntitiesAt(newPos)) == 0 {
 			validMove = &dir
 			break
 		}
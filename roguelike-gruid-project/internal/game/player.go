@@ -2,6 +2,8 @@ package game
 
 import (
 	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
 	"github.com/sirupsen/logrus"
 )
 
@@ -13,7 +15,47 @@ const (
 	ActionS
 	ActionN
 	ActionE
+	ActionNW
+	ActionNE
+	ActionSW
+	ActionSE
+	ActionRest
+	ActionDescend
+	ActionAscend
+	ActionPickup
+	ActionInventory
+	ActionDrop
+	ActionUse
+	ActionEquip
+	ActionCharacter
+	ActionLook
+	ActionExplore
+	ActionMessageLog
+	ActionHelp
 	ActionQuit
+
+	// Targeting mode actions
+	ActionNextTarget
+	ActionConfirm
+	ActionCancel
+
+	// Screen actions
+	ActionClose
+	ActionSearch
+	ActionNextMatch
+	ActionPrevMatch
+	ActionNewRun
+
+	// Menu actions
+	ActionPrevEntry
+	ActionNextEntry
+
+	// Replay actions
+	ActionPause
+	ActionStep
+	ActionFaster
+	ActionSlower
+	ActionFastForward
 )
 
 type actionError int
@@ -39,7 +81,7 @@ func (md *Model) normalModeAction(playerAction playerAction) (again bool, eff gr
 	case ActionNone:
 		again = true
 		err = actionErrorUnknown
-	case ActionW, ActionS, ActionN, ActionE:
+	case ActionW, ActionS, ActionN, ActionE, ActionNW, ActionNE, ActionSW, ActionSE:
 		action := MoveAction{
 			Direction: keyToDir(playerAction),
 			EntityID:  g.PlayerID,
@@ -49,6 +91,50 @@ func (md *Model) normalModeAction(playerAction playerAction) (again bool, eff gr
 
 		return false, eff, nil
 
+	case ActionRest:
+		return md.rest()
+
+	case ActionDescend, ActionAscend:
+		return md.takeStairs(playerAction)
+
+	case ActionPickup:
+		return md.pickup()
+
+	case ActionInventory:
+		md.openInventory(inventoryView)
+		again = true
+
+	case ActionDrop:
+		md.openInventory(inventoryDrop)
+		again = true
+
+	case ActionUse:
+		md.openInventory(inventoryUse)
+		again = true
+
+	case ActionEquip:
+		md.openInventory(inventoryEquip)
+		again = true
+
+	case ActionCharacter:
+		md.mode = modeCharacter
+		again = true
+
+	case ActionLook:
+		md.startLook()
+		again = true
+
+	case ActionExplore:
+		return md.explore()
+
+	case ActionMessageLog:
+		md.openMessageHistory()
+		again = true
+
+	case ActionHelp:
+		md.openHelp()
+		again = true
+
 	default:
 		logrus.Debugf("Unknown action: %v\n", playerAction)
 		err = actionErrorUnknown
@@ -62,3 +148,47 @@ func (md *Model) normalModeAction(playerAction playerAction) (again bool, eff gr
 	return again, eff, err
 
 }
+
+// takeStairs queues a stairs action if the player stands on matching stairs.
+func (md *Model) takeStairs(playerAction playerAction) (again bool, eff gruid.Effect, err error) {
+	g := md.game
+	pos, _ := g.ecs.GetPosition(g.PlayerID)
+	cell := g.dungeon.Grid.At(pos)
+
+	var action GameAction
+	switch {
+	case playerAction == ActionDescend && cell == StairsDownCell:
+		action = DescendAction{EntityID: g.PlayerID}
+	case playerAction == ActionAscend && cell == StairsUpCell:
+		action = AscendAction{EntityID: g.PlayerID}
+	case playerAction == ActionDescend:
+		g.log.AddMessagef(ui.ColorUIText, "There are no stairs down here.")
+		return true, nil, nil
+	default:
+		g.log.AddMessagef(ui.ColorUIText, "There are no stairs up here.")
+		return true, nil, nil
+	}
+
+	actor, _ := g.ecs.GetTurnActor(g.PlayerID)
+	actor.AddAction(action)
+	return false, nil, nil
+}
+
+// pickup queues a pickup action if there is an item under the player and room
+// for it in the inventory.
+func (md *Model) pickup() (again bool, eff gruid.Effect, err error) {
+	g := md.game
+	pos, _ := g.ecs.GetPosition(g.PlayerID)
+	if len(g.ecs.GetEntitiesAtWithComponents(pos, components.CItem)) == 0 {
+		g.log.AddMessagef(ui.ColorUIText, "There is nothing here.")
+		return true, nil, nil
+	}
+	if inv, _ := g.ecs.GetInventory(g.PlayerID); inv.Full() {
+		g.log.AddMessagef(ui.ColorUIText, "Your inventory is full.")
+		return true, nil, nil
+	}
+
+	actor, _ := g.ecs.GetTurnActor(g.PlayerID)
+	actor.AddAction(PickupAction{EntityID: g.PlayerID})
+	return false, nil, nil
+}
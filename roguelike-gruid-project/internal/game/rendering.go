@@ -26,11 +26,14 @@ func RenderOrder(ecs *ecs.ECS, id ecs.EntityID) (ro renderOrder) {
 	isPlayer := ecs.HasComponent(id, components.CPlayerTag)
 	isMonster := ecs.HasComponent(id, components.CAITag)
 	isCorpse := ecs.HasComponent(id, components.CCorpseTag)
+	isItem := ecs.HasComponent(id, components.CItem)
 
 	if isPlayer {
 		ro = ROActor
 	} else if isMonster {
 		ro = ROActor
+	} else if isItem {
+		ro = ROItem
 	} else if isCorpse {
 		ro = ROCorpse
 	}
@@ -55,6 +58,27 @@ func (md *Model) Draw() gruid.Grid {
 		return md.grid // Return the grid even if FOV is missing
 	}
 
+	// The message history pager, the death, character and help screens take
+	// the whole screen
+	switch md.mode {
+	case modeMessageLog:
+		md.drawMessageHistory()
+		return md.grid
+	case modeGameOver:
+		md.drawGameOver()
+		return md.grid
+	case modeCharacter:
+		md.drawCharacter()
+		return md.grid
+	case modeHelp:
+		md.help.Draw()
+		return md.grid
+	case modeInventory:
+		// The map underneath does not change while choosing an item
+		md.drawInventory()
+		return md.grid
+	}
+
 	// Clear the grid before drawing
 	md.grid.Fill(gruid.Cell{Rune: ' '})
 
@@ -64,6 +88,17 @@ func (md *Model) Draw() gruid.Grid {
 	// Render entities using the ECS RenderSystem, passing player FOV if available
 	md.renderEntitiesSystem(g.ecs, playerFOVComp, g.dungeon.Width)
 
+	// Draw the status bar and the latest messages below the map
+	md.drawHUD(playerFOVComp)
+	md.drawLogPanel()
+
+	switch {
+	case md.mode == modeTargeting:
+		md.drawTargeting()
+	case md.hovering:
+		md.drawHover()
+	}
+
 	return md.grid
 }
 
@@ -82,8 +117,11 @@ func (md *Model) drawMap(g *Game, playerFOV *components.FOV) {
 
 		// Use the new helper function to get the appropriate style
 		style := ui.GetMapStyle(isWall, isVisible, isExplored)
+		if isVisible && g.dungeon.IsStairs(p) {
+			style.Fg = ui.ColorStairs
+		}
 
-		md.grid.Set(p, gruid.Cell{
+		md.viewport.Set(p, gruid.Cell{
 			Rune:  g.dungeon.Rune(it.Cell()),
 			Style: style,
 		})
@@ -127,7 +165,7 @@ func (md *Model) renderEntitiesSystem(world *ecs.ECS, playerFOV *components.FOV,
 		if bucket, ok := orderBuckets[priority]; ok {
 			for _, id := range bucket {
 				pos, _ := world.GetPosition(id)
-				drawEntity(world, pos, id, md.grid)
+				drawEntity(world, pos, id, md.viewport)
 			}
 		}
 	}
@@ -140,8 +178,31 @@ func drawEntity(ecs *ecs.ECS, pos gruid.Point, entityID ecs.EntityID, grid gruid
 		return
 	}
 
-	color := renderable.Color
+	color := monsterColor(ecs, entityID, renderable.Color)
 
 	// Draw the entity with the appropriate color
 	grid.Set(pos, gruid.Cell{Rune: renderable.Glyph, Style: gruid.Style{Fg: color}})
 }
+
+// monsterColor returns the tint of a monster glyph of the given color, showing
+// its condition. Other entities keep their color.
+func monsterColor(world *ecs.ECS, id ecs.EntityID, color gruid.Color) gruid.Color {
+	if world.HasComponent(id, components.CConfused) {
+		return ui.ColorConfusedMonster
+	}
+	if state, ok := world.GetAIState(id); ok {
+		color = aiStateColor(state, color)
+	}
+	return color
+}
+
+// aiStateColor returns the tint of a monster glyph in the given AI state.
+func aiStateColor(state components.AIState, color gruid.Color) gruid.Color {
+	switch state {
+	case components.AIAsleep:
+		return ui.ColorSleepingMonster
+	case components.AIFleeing:
+		return ui.ColorFleeingMonster
+	}
+	return color
+}
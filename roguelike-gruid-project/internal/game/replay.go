@@ -0,0 +1,346 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"codeberg.org/anaseto/gruid"
+	gui "codeberg.org/anaseto/gruid/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingVersion is the version of the replay format. Replays written with
+// another version are rejected.
+const recordingVersion = 2
+
+const (
+	replayInterval = 200 * time.Millisecond // delay between messages at speed 1
+	replayMaxSpeed = 64
+	replayMinSpeed = 0.25
+)
+
+func init() {
+	// Recorded messages are stored as interface values.
+	gob.Register(gruid.MsgKeyDown{})
+	gob.Register(gruid.MsgMouse{})
+}
+
+// Recording holds the input messages received by a Model, along with what is
+// needed to reproduce the run they drove.
+type Recording struct {
+	Version int
+	Seed    int64
+	Save    []byte       // initial saved state, if the run was resumed from a save
+	Rules   config.Rules // settings of the recorded run
+	Data    dataDigests  // data files of the recorded run
+	Msgs    []gruid.Msg
+}
+
+// dataDigests identifies the monsters, items, vaults and key bindings a run
+// was played with. A replay only gives the same run with the same ones.
+type dataDigests struct {
+	Monsters string
+	Items    string
+	Vaults   string
+	Keys     string
+}
+
+// currentDataDigests returns the digests of the data in use.
+func currentDataDigests() dataDigests {
+	return dataDigests{
+		Monsters: digest(monsterBestiary.Templates),
+		Items:    digest(itemCatalog.Templates),
+		Vaults:   digest(vaultLibrary.Templates),
+		Keys:     digest(keymap),
+	}
+}
+
+// digest returns a short hash of the JSON encoding of v.
+func digest(v any) string {
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(v); err != nil {
+		panic(fmt.Sprintf("digest: %v", err))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// RestoreConfig makes the rules of the recorded run the configured ones. It
+// returns an error if the data in use differ from those of the run, as it
+// would then play differently.
+func (rec *Recording) RestoreConfig() error {
+	current := currentDataDigests()
+	var errs []error
+	check := func(recorded, current, what, file string) {
+		if recorded != current {
+			errs = append(errs, fmt.Errorf("the %s differ from those of the recorded run (see %s)", what, file))
+		}
+	}
+	check(rec.Data.Monsters, current.Monsters, "monsters", config.BestiaryFileName)
+	check(rec.Data.Items, current.Items, "items", config.ItemsFileName)
+	check(rec.Data.Vaults, current.Vaults, "vaults", config.VaultsFileName)
+	check(rec.Data.Keys, current.Keys, "key bindings", config.KeysFileName)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("cannot replay: %w", err)
+	}
+	config.Config.Rules = rec.Rules
+	return nil
+}
+
+// Write writes the recording to w as gzip'd gob.
+func (rec *Recording) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := gob.NewEncoder(zw).Encode(rec); err != nil {
+		return fmt.Errorf("encode replay: %w", err)
+	}
+	return zw.Close()
+}
+
+// ReadRecording reads a recording written by Write.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read replay: %w", err)
+	}
+	defer zr.Close()
+
+	rec := &Recording{}
+	if err := gob.NewDecoder(zr).Decode(rec); err != nil {
+		return nil, fmt.Errorf("decode replay: %w", err)
+	}
+	if rec.Version != recordingVersion {
+		return nil, fmt.Errorf("unsupported replay version %d (expected %d)", rec.Version, recordingVersion)
+	}
+	return rec, nil
+}
+
+// LoadRecording loads a recording from the given file.
+func LoadRecording(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRecording(f)
+}
+
+// RecordTo makes the model record its input and write it to the given file
+// when the game ends. It must be called before the model is started.
+func (md *Model) RecordTo(path string) {
+	md.recordPath = path
+}
+
+// record appends user input messages to the recording.
+func (md *Model) record(msg gruid.Msg) {
+	if md.recording == nil {
+		return
+	}
+	switch msg.(type) {
+	case gruid.MsgKeyDown, gruid.MsgMouse:
+		md.recording.Msgs = append(md.recording.Msgs, msg)
+	}
+}
+
+// writeRecording writes the recording to the replay file, if any.
+func (md *Model) writeRecording() {
+	if md.recording == nil || md.recordPath == "" {
+		return
+	}
+	var buf bytes.Buffer
+	if err := md.recording.Write(&buf); err != nil {
+		logrus.WithError(err).Error("Could not write replay")
+		return
+	}
+	if err := os.WriteFile(md.recordPath, buf.Bytes(), 0o644); err != nil {
+		logrus.WithError(err).Error("Could not write replay")
+		return
+	}
+	logrus.Infof("Replay written to %s", md.recordPath)
+}
+
+// newReplayTarget returns a model set up in the initial state of the recorded
+// run, which neither saves nor records. The rules of the run are restored, and
+// the grid must have the matching size.
+func newReplayTarget(grid gruid.Grid, rec *Recording) (*Model, error) {
+	if err := rec.RestoreConfig(); err != nil {
+		return nil, err
+	}
+	if size := grid.Size(); size.X != config.Config.UIWidth() || size.Y != config.Config.UIHeight() {
+		return nil, fmt.Errorf("replay needs a %dx%d grid, got %dx%d",
+			config.Config.UIWidth(), config.Config.UIHeight(), size.X, size.Y)
+	}
+	md := NewModel(grid, rec.Seed)
+	if rec.Save == nil {
+		md.startGame()
+		return md, nil
+	}
+	g, err := LoadGame(bytes.NewReader(rec.Save))
+	if err != nil {
+		return nil, err
+	}
+	md.resumeGame(g)
+	return md, nil
+}
+
+// PlayRecording replays all the recorded input at once on a fresh model, and
+// returns the model in its final state. It is meant for regression fixtures.
+func PlayRecording(grid gruid.Grid, rec *Recording) (*Model, error) {
+	md, err := newReplayTarget(grid, rec)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range rec.Msgs {
+		md.Update(msg)
+	}
+	return md, nil
+}
+
+// msgReplayTick is sent to the replay model when the next recorded message is
+// due. It carries the id of the tick sequence it belongs to.
+type msgReplayTick int
+
+// ReplayModel implements gruid.Model and plays a recording back at a chosen
+// speed, with pause, step and fast-forward controls.
+type ReplayModel struct {
+	grid        gruid.Grid
+	rec         *Recording
+	model       *Model
+	next        int     // index of the next message to play
+	speed       float64 // playback speed multiplier
+	paused      bool
+	fastForward bool
+	tickID      int // current tick sequence, older ticks are ignored
+}
+
+// NewReplayModel returns a model playing back the given recording.
+func NewReplayModel(grid gruid.Grid, rec *Recording, speed float64) (*ReplayModel, error) {
+	md, err := newReplayTarget(grid, rec)
+	if err != nil {
+		return nil, err
+	}
+	if speed <= 0 {
+		speed = 1
+	}
+	return &ReplayModel{grid: grid, rec: rec, model: md, speed: speed}, nil
+}
+
+// Update implements gruid.Model.Update.
+func (rm *ReplayModel) Update(msg gruid.Msg) gruid.Effect {
+	switch msg := msg.(type) {
+	case gruid.MsgInit:
+		if runtime.GOOS == "js" {
+			return rm.tick()
+		}
+		return gruid.Batch(rm.tick(), gruid.Sub(utils.HandleSignals))
+	case gruid.MsgQuit:
+		return gruid.End()
+	case msgReplayTick:
+		if int(msg) != rm.tickID || rm.paused {
+			return nil
+		}
+		rm.step()
+		return rm.tick()
+	case gruid.MsgKeyDown:
+		return rm.updateKeyDown(msg)
+	}
+	return nil
+}
+
+func (rm *ReplayModel) updateKeyDown(msg gruid.MsgKeyDown) gruid.Effect {
+	switch keymap.action(keymapReplay, msg.Key) {
+	case ActionPause:
+		rm.paused = !rm.paused
+		if !rm.paused {
+			return rm.tick()
+		}
+	case ActionStep:
+		if rm.paused {
+			rm.step()
+		}
+	case ActionFaster:
+		rm.speed = min(rm.speed*2, replayMaxSpeed)
+		return rm.tick()
+	case ActionSlower:
+		rm.speed = max(rm.speed/2, replayMinSpeed)
+		return rm.tick()
+	case ActionFastForward:
+		rm.fastForward = !rm.fastForward
+		return rm.tick()
+	case ActionQuit:
+		return gruid.End()
+	}
+	return nil
+}
+
+// step feeds the next recorded message to the replayed model. Effects such as
+// quitting are ignored: the replay decides when to end.
+func (rm *ReplayModel) step() {
+	if rm.finished() {
+		return
+	}
+	rm.model.Update(rm.rec.Msgs[rm.next])
+	rm.next++
+}
+
+func (rm *ReplayModel) finished() bool {
+	return rm.next >= len(rm.rec.Msgs)
+}
+
+// tick starts a new tick sequence for the next message, cancelling any
+// pending one.
+func (rm *ReplayModel) tick() gruid.Effect {
+	rm.tickID++
+	if rm.paused || rm.finished() {
+		return nil
+	}
+	id := rm.tickID
+	d := time.Duration(float64(replayInterval) / rm.speed)
+	if rm.fastForward {
+		d = 0
+	}
+	return gruid.Cmd(func() gruid.Msg {
+		time.Sleep(d)
+		return msgReplayTick(id)
+	})
+}
+
+// Draw implements gruid.Model.Draw. It draws the replayed model with a status
+// line at the bottom.
+func (rm *ReplayModel) Draw() gruid.Grid {
+	rm.model.Draw()
+
+	rg := rm.grid.Range()
+	line := rm.grid.Slice(rg.Line(rg.Size().Y - 1))
+	line.Fill(gruid.Cell{Rune: ' '})
+
+	state := fmt.Sprintf("x%g", rm.speed)
+	switch {
+	case rm.finished():
+		state = "finished"
+	case rm.paused:
+		state = "paused"
+	case rm.fastForward:
+		state = "fast-forward"
+	}
+	status := fmt.Sprintf("REPLAY %d/%d [%s]  %s", rm.next, len(rm.rec.Msgs), state, joinHints(
+		keyHint(keymapReplay, ActionPause, "pause"),
+		keyHint(keymapReplay, ActionStep, "step"),
+		keyHint(keymapReplay, ActionFaster, "faster"),
+		keyHint(keymapReplay, ActionSlower, "slower"),
+		keyHint(keymapReplay, ActionFastForward, "fast"),
+		keyHint(keymapReplay, ActionQuit, "quit")))
+	gui.NewStyledText(status, gruid.Style{Fg: ui.ColorUIHighlight}).Draw(line)
+	return rm.grid
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/vaults"
+)
+
+// replayFixture is a short recorded run, played back to catch changes in the
+// game behavior, with the expected final state in replayFixture.golden. Run
+// the tests with UPDATE_GOLDEN=1 to record both again after an intended
+// change, with the seed and keys below.
+const replayFixture = "testdata/short.replay"
+
+const replayFixtureSeed = 12
+
+var replayFixtureKeys = []gruid.Key{
+	"l", "l", "l", "j", "j", "h", "h", "k", "l", "j", "q",
+}
+
+// recordFixture records the run driven by replayFixtureKeys to path.
+func recordFixture(t *testing.T, path string) {
+	t.Helper()
+	md := NewModel(newTestGrid(), replayFixtureSeed)
+	md.savePath = ""
+	md.RecordTo(path)
+	md.Update(gruid.MsgInit{})
+	for _, key := range replayFixtureKeys {
+		md.Update(gruid.MsgKeyDown{Key: key})
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("recording not written: %v", err)
+	}
+}
+
+// finalState describes the state of a game at the end of a replay.
+func finalState(g *Game) string {
+	pos, _ := g.ecs.GetPosition(g.PlayerID)
+	hp, _ := g.ecs.GetHealth(g.PlayerID)
+	return fmt.Sprintf("depth %d, turn %d, kills %d, player at %d,%d with %d/%d HP",
+		g.Depth, g.stats.Turns, g.stats.Kills, pos.X, pos.Y, hp.CurrentHP, hp.MaxHP)
+}
+
+func TestReplayFixture(t *testing.T) {
+	setRules(t, config.Default().Rules)
+	golden := replayFixture + ".golden"
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		recordFixture(t, replayFixture)
+	}
+	rec, err := LoadRecording(replayFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Play the recording back like the game does, with the headless driver
+	grid := newTestGrid()
+	rm, err := NewReplayModel(grid, rec, replayMaxSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := grid.Size()
+	driver := ui.NewHeadless(size.X, size.Y)
+	app := gruid.NewApp(gruid.AppConfig{Model: rm, Driver: driver})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- app.Start(ctx) }()
+
+	driver.Send(gruid.MsgKeyDown{Key: "f"})
+	for !strings.Contains(driver.String(), "[finished]") {
+		select {
+		case err := <-done:
+			t.Fatalf("replay ended before finishing: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	driver.Send(gruid.MsgKeyDown{Key: "q"})
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	got := finalState(rm.model.game) + "\n" + driver.String()
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("final state differs from %s:\n%s\nwant:\n%s", golden, got, want)
+	}
+
+	// Playing the recording at once gives the same run
+	md, err := PlayRecording(newTestGrid(), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := finalState(md.game); !strings.HasPrefix(string(want), state+"\n") {
+		t.Errorf("PlayRecording: %s, want the replayed state", state)
+	}
+}
+
+func TestRecordingRestoresRules(t *testing.T) {
+	setRules(t, config.Default().Rules)
+	rules := config.Default().Rules
+	rules.Generator = "caves"
+	rules.MaxVaults = 3
+	rec := &Recording{Version: recordingVersion, Seed: 1, Rules: rules, Data: currentDataDigests()}
+
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.RestoreConfig(); err != nil {
+		t.Fatalf("RestoreConfig: %v", err)
+	}
+	if config.Config.Rules != rules {
+		t.Errorf("rules = %+v, want those of the recording %+v", config.Config.Rules, rules)
+	}
+}
+
+func TestRecordingRefusesOtherData(t *testing.T) {
+	setRules(t, config.Default().Rules)
+	rec := &Recording{Version: recordingVersion, Seed: 1, Rules: config.Default().Rules, Data: currentDataDigests()}
+
+	saved := vaultLibrary
+	t.Cleanup(func() { vaultLibrary = saved })
+	vaultLibrary = &vaults.Library{}
+
+	err := rec.RestoreConfig()
+	if err == nil || !strings.Contains(err.Error(), config.VaultsFileName) {
+		t.Errorf("RestoreConfig with other vaults: error %v, want one about %s", err, config.VaultsFileName)
+	}
+	if _, err := PlayRecording(newTestGrid(), rec); err == nil {
+		t.Error("PlayRecording with other vaults: no error")
+	}
+}
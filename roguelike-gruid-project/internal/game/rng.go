@@ -0,0 +1,54 @@
+package game
+
+import "math/rand"
+
+// rngSource wraps a math/rand source and counts the values drawn from it, so
+// that the generator state can be saved and restored.
+type rngSource struct {
+	seed  int64
+	calls uint64
+	src   rand.Source64
+}
+
+// rngState is the serialized form of an rngSource.
+type rngState struct {
+	Seed  int64
+	Calls uint64
+}
+
+func newRNGSource(seed int64) *rngSource {
+	return &rngSource{
+		seed: seed,
+		src:  rand.NewSource(seed).(rand.Source64),
+	}
+}
+
+// restoreRNGSource returns a source in the same state as the saved one, by
+// drawing the same number of values from a source with the same seed.
+func restoreRNGSource(st rngState) *rngSource {
+	s := newRNGSource(st.Seed)
+	for s.calls < st.Calls {
+		s.Int63()
+	}
+	return s
+}
+
+func (s *rngSource) Int63() int64 {
+	s.calls++
+	return s.src.Int63()
+}
+
+func (s *rngSource) Uint64() uint64 {
+	s.calls++
+	return s.src.Uint64()
+}
+
+func (s *rngSource) Seed(seed int64) {
+	s.seed = seed
+	s.calls = 0
+	s.src.Seed(seed)
+}
+
+func (s *rngSource) state() rngState {
+	return rngState{Seed: s.seed, Calls: s.calls}
+}
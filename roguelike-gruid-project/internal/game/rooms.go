@@ -1,7 +1,10 @@
 package game
 
 import (
+	"math/rand"
+
 	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
 	"codeberg.org/anaseto/gruid/rl"
 )
 
@@ -28,8 +31,51 @@ func (r Rect) Intersects(other Rect) bool {
 		r.Y1 <= other.Y2 && r.Y2 >= other.Y1
 }
 
-// Constants for map generation (roomMaxSize, roomMinSize, maxRooms)
-// are now defined centrally (e.g., in game.go)
+// Contains reports whether the point is within the rectangle, edges included.
+func (r Rect) Contains(p gruid.Point) bool {
+	return p.X >= r.X1 && p.X <= r.X2 && p.Y >= r.Y1 && p.Y <= r.Y2
+}
+
+// Region returns the floor of the room carved by createRoom.
+func (r Rect) Region() Region {
+	var reg Region
+	for y := r.Y1 + 1; y < r.Y2; y++ {
+		for x := r.X1 + 1; x < r.X2; x++ {
+			reg = append(reg, gruid.Point{X: x, Y: y})
+		}
+	}
+	return reg
+}
+
+// rectRegions returns the floor regions of rooms carved by createRoom.
+func rectRegions(rooms []Rect) []Region {
+	regions := make([]Region, len(rooms))
+	for i, room := range rooms {
+		regions[i] = room.Region()
+	}
+	return regions
+}
+
+// Region is a set of floor positions forming a part of a level, such as a
+// room or a part of a cave, in which monsters and items are spawned.
+type Region []gruid.Point
+
+// Center returns the position of the region closest to its center of mass.
+// For a room, it is the center of its rectangle.
+func (reg Region) Center() gruid.Point {
+	var sum gruid.Point
+	for _, p := range reg {
+		sum = sum.Add(p)
+	}
+	center := gruid.Point{X: sum.X / len(reg), Y: sum.Y / len(reg)}
+	best := reg[0]
+	for _, p := range reg {
+		if paths.DistanceManhattan(p, center) < paths.DistanceManhattan(best, center) {
+			best = p
+		}
+	}
+	return best
+}
 
 // createRoom carves a rectangular room onto the grid.
 // It now sets floor cells with the TileFloor attribute.
@@ -41,6 +87,18 @@ func createRoom(grid rl.Grid, room Rect) {
 	}
 }
 
+// createLTunnel carves an L-shaped tunnel between two points, going first
+// horizontally or vertically at random.
+func createLTunnel(grid rl.Grid, rng *rand.Rand, from, to gruid.Point) {
+	if rng.Intn(2) == 0 {
+		createHTunnel(grid, from.X, to.X, from.Y)
+		createVTunnel(grid, from.Y, to.Y, to.X)
+	} else {
+		createVTunnel(grid, from.Y, to.Y, from.X)
+		createHTunnel(grid, from.X, to.X, to.Y)
+	}
+}
+
 // createHTunnel carves a horizontal tunnel between two points.
 func createHTunnel(grid rl.Grid, x1, x2, y int) {
 	startX := min(x1, x2)
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// playerRegenTurns is the number of turns it takes the player to regain one
+// hit point.
+const playerRegenTurns = 10
+
+// running is the state of a run in a direction.
+type running struct {
+	Dir      gruid.Point // direction of the last step
+	Corridor bool        // following a corridor, rather than crossing a room
+	Around   uint8       // walkable neighbors of the last position, as a bitmask
+	Steps    int
+}
+
+// run starts running in the given direction, unless a monster is in view.
+func (md *Model) run(dir gruid.Point) (again bool, eff gruid.Effect, err error) {
+	g := md.game
+	if g.monstersInView() {
+		g.log.AddMessagef(ui.ColorUIText, "Not with monsters in view.")
+		return true, nil, nil
+	}
+
+	g.startAutoMove(autoRun)
+	g.auto.Run.Dir = dir
+	return false, nil, nil
+}
+
+// runAction returns the next step of a run. In a corridor, the run follows its
+// turns and stops at junctions or where it opens up. Elsewhere, the run goes
+// straight and stops as soon as the surroundings change, like at a door or a
+// room corner. It also stops on stairs and before anything in the way.
+func (g *Game) runAction() GameAction {
+	pos, _ := g.ecs.GetPosition(g.PlayerID)
+	run := &g.auto.Run
+	cardinal := run.Dir.X == 0 || run.Dir.Y == 0
+
+	if run.Steps == 0 {
+		run.Corridor = cardinal && g.inCorridor(pos)
+	} else {
+		if g.dungeon.IsStairs(pos) {
+			return nil
+		}
+		switch {
+		case run.Corridor && !g.inCorridor(pos):
+			return nil
+		case run.Corridor:
+			nb := paths.Neighbors{}
+			exits := nb.Cardinal(pos, func(q gruid.Point) bool {
+				return q != pos.Sub(run.Dir) && g.dungeon.isWalkable(q)
+			})
+			if len(exits) != 1 {
+				return nil
+			}
+			run.Dir = exits[0].Sub(pos)
+		case g.walkableAround(pos) != run.Around:
+			return nil
+		}
+	}
+	run.Around = g.walkableAround(pos)
+
+	next := pos.Add(run.Dir)
+	if !g.dungeon.isWalkable(next) || len(g.spatialGrid.GetEntitiesAt(next)) > 0 {
+		return nil
+	}
+	run.Steps++
+	return MoveAction{Direction: run.Dir, EntityID: g.PlayerID}
+}
+
+// inCorridor reports whether the position has at most two walkable cardinal
+// neighbors.
+func (g *Game) inCorridor(p gruid.Point) bool {
+	nb := paths.Neighbors{}
+	return len(nb.Cardinal(p, g.dungeon.isWalkable)) <= 2
+}
+
+// walkableAround returns a bitmask of the walkable neighbors of a position.
+func (g *Game) walkableAround(p gruid.Point) uint8 {
+	var mask uint8
+	nb := paths.Neighbors{}
+	for i, q := range nb.All(p, func(gruid.Point) bool { return true }) {
+		if g.dungeon.isWalkable(q) {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// rest starts resting until healed, unless there is no need or a monster is
+// in view.
+func (md *Model) rest() (again bool, eff gruid.Effect, err error) {
+	g := md.game
+	if health, _ := g.ecs.GetHealth(g.PlayerID); health.CurrentHP >= health.MaxHP {
+		g.log.AddMessagef(ui.ColorUIText, "You are already at full health.")
+		return true, nil, nil
+	}
+	if g.monstersInView() {
+		g.log.AddMessagef(ui.ColorUIText, "Not with monsters in view.")
+		return true, nil, nil
+	}
+
+	g.startAutoMove(autoRest)
+	return false, nil, nil
+}
+
+// restAction returns the next action of a rest: waiting, until healed.
+func (g *Game) restAction() GameAction {
+	if health, _ := g.ecs.GetHealth(g.PlayerID); health.CurrentHP >= health.MaxHP {
+		g.log.AddMessagef(ui.ColorStatusGood, "You feel rested.")
+		return nil
+	}
+	return WaitAction{EntityID: g.PlayerID}
+}
+
+// regenerate heals the player a little every playerRegenTurns turns.
+func (g *Game) regenerate() {
+	if g.stats.Turns%playerRegenTurns != 0 {
+		return
+	}
+	health, ok := g.ecs.GetHealth(g.PlayerID)
+	if !ok || health.IsDead() || health.CurrentHP >= health.MaxHP {
+		return
+	}
+	health.CurrentHP++
+	g.ecs.AddComponent(g.PlayerID, components.CHealth, health)
+}
@@ -0,0 +1,233 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/combat"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/log"
+	turn "github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/turn_queue"
+	"github.com/sirupsen/logrus"
+)
+
+// saveVersion is the version of the save format. Saves written with another
+// version are rejected.
+const saveVersion = 8
+
+func init() {
+	// Queued actions are stored in TurnActor components as interface values.
+	gob.Register(WaitAction{})
+	gob.Register(MoveAction{})
+	gob.Register(AttackAction{})
+	gob.Register(DescendAction{})
+	gob.Register(AscendAction{})
+	gob.Register(PickupAction{})
+	gob.Register(DropAction{})
+	gob.Register(UseItemAction{})
+	gob.Register(EquipAction{})
+	gob.Register(UnequipAction{})
+}
+
+// saveData is the serialized form of a Game.
+type saveData struct {
+	Version   int
+	Depth     int
+	PlayerID  ecs.EntityID
+	Stats     runStats
+	Noise     noise
+	ECS       *ecs.ECS
+	TurnQueue *turn.TurnQueue
+	Dungeon   *Map
+	Levels    map[int]*Level
+	Log       *log.MessageLog
+	RNG       rngState
+	Rules     config.Rules
+}
+
+// Save writes the full game state to w as gzip'd gob.
+func (g *Game) Save(w io.Writer) error {
+	data := saveData{
+		Version:   saveVersion,
+		Depth:     g.Depth,
+		PlayerID:  g.PlayerID,
+		Stats:     g.stats,
+		Noise:     g.noise,
+		ECS:       g.ecs,
+		TurnQueue: g.turnQueue,
+		Dungeon:   g.dungeon,
+		Levels:    g.levels,
+		Log:       g.log,
+		RNG:       g.rng.state(),
+		Rules:     g.rules,
+	}
+
+	zw := gzip.NewWriter(w)
+	if err := gob.NewEncoder(zw).Encode(data); err != nil {
+		return fmt.Errorf("encode save: %w", err)
+	}
+	return zw.Close()
+}
+
+// LoadGame reads a game state written by Save.
+func LoadGame(r io.Reader) (*Game, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read save: %w", err)
+	}
+	defer zr.Close()
+
+	var data saveData
+	if err := gob.NewDecoder(zr).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode save: %w", err)
+	}
+	if data.Version != saveVersion {
+		return nil, fmt.Errorf("unsupported save version %d (expected %d)", data.Version, saveVersion)
+	}
+
+	g := &Game{
+		Depth:       data.Depth,
+		PlayerID:    data.PlayerID,
+		stats:       data.Stats,
+		noise:       data.Noise,
+		ecs:         data.ECS,
+		turnQueue:   data.TurnQueue,
+		dungeon:     data.Dungeon,
+		levels:      data.Levels,
+		log:         data.Log,
+		spatialGrid: NewSpatialGrid(data.Dungeon.Width, data.Dungeon.Height),
+		rules:       data.Rules,
+		resolver:    combat.DefaultResolver(),
+	}
+	if g.levels == nil {
+		g.levels = make(map[int]*Level)
+	}
+	g.rng = restoreRNGSource(data.RNG)
+	g.rand = rand.New(g.rng)
+	g.rebuildSpatialGrid()
+
+	return g, nil
+}
+
+// rebuildSpatialGrid indexes every entity that blocks movement, as spawning
+// does.
+func (g *Game) rebuildSpatialGrid() {
+	g.spatialGrid.Clear()
+	for _, id := range g.ecs.GetEntitiesWithComponents(components.CPosition, components.CBlocksMovement) {
+		pos, _ := g.ecs.GetPosition(id)
+		g.spatialGrid.Add(id, pos)
+	}
+}
+
+// SaveFile saves the game to the given path. The save is first written to a
+// temporary file, so that a failed save does not corrupt a previous one.
+func (g *Game) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create save: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := g.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write save: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadGameFile loads a game saved with SaveFile.
+func LoadGameFile(path string) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadGame(f)
+}
+
+// loadSavedGame resumes the saved game, if any. It returns false when there is
+// no usable save.
+func (md *Model) loadSavedGame() bool {
+	if md.savePath == "" {
+		return false
+	}
+	data, err := os.ReadFile(md.savePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.WithError(err).Warn("Could not read saved game")
+		}
+		return false
+	}
+	g, err := LoadGame(bytes.NewReader(data))
+	if err != nil {
+		logrus.WithError(err).Warn("Could not load saved game")
+		return false
+	}
+	cfg := config.Config
+	if g.rules.DungeonWidth != cfg.DungeonWidth || g.rules.DungeonHeight != cfg.DungeonHeight {
+		logrus.Warnf("Could not load saved game: its map is %dx%d, but the configured size is %dx%d",
+			g.rules.DungeonWidth, g.rules.DungeonHeight, cfg.DungeonWidth, cfg.DungeonHeight)
+		return false
+	}
+	if g.rules != cfg.Rules {
+		logrus.Warnf("The saved game goes on with the settings it was started with: %+v", g.rules)
+		cfg.Rules = g.rules
+	}
+
+	logrus.Infof("Resuming saved game from %s", md.savePath)
+	if md.recording != nil {
+		// Replays of a resumed game start from the saved state
+		md.recording.Save = data
+		md.recording.Rules = g.rules
+	}
+	md.resumeGame(g)
+	return true
+}
+
+// resumeGame makes g the current game and runs turns until the player can
+// act. The damage resolver of the current game is kept.
+func (md *Model) resumeGame(g *Game) {
+	g.SetDamageResolver(md.game.resolver)
+	md.game = g
+	md.processTurnQueue()
+}
+
+// saveGame saves the current game, unless the run is over.
+func (md *Model) saveGame() {
+	if md.savePath == "" || md.game.state == StateGameOver {
+		return
+	}
+	if err := md.game.SaveFile(md.savePath); err != nil {
+		logrus.WithError(err).Error("Could not save game")
+		return
+	}
+	logrus.Infof("Game saved to %s", md.savePath)
+}
+
+// deleteSave removes the save file: death is permanent.
+func (md *Model) deleteSave() {
+	if md.savePath == "" {
+		return
+	}
+	if err := os.Remove(md.savePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logrus.WithError(err).Error("Could not delete save")
+	}
+}
+
+// SaveTo makes the model resume the game saved at the given path on launch,
+// and save to it on quit. It must be called before the model is started.
+func (md *Model) SaveTo(path string) {
+	md.savePath = path
+}
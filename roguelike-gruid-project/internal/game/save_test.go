@@ -0,0 +1,213 @@
+package game
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+)
+
+// setRules changes the configured rules for the duration of the test.
+func setRules(t *testing.T, rules config.Rules) {
+	t.Helper()
+	saved := config.Config.Rules
+	t.Cleanup(func() { config.Config.Rules = saved })
+	config.Config.Rules = rules
+}
+
+func newTestGrid() gruid.Grid {
+	return gruid.NewGrid(config.Config.UIWidth(), config.Config.UIHeight())
+}
+
+// playedGame returns a game after a few turns of play on the first level, then
+// on the second, with an attack in the message log and an action queued for a
+// monster.
+func playedGame(t *testing.T) *Game {
+	t.Helper()
+	md := NewModel(newTestGrid(), 1)
+	md.savePath = ""
+	md.startGame()
+	for _, key := range []gruid.Key{"l", "l", "j", "j", "h", "k", "k", "l"} {
+		md.Update(gruid.MsgKeyDown{Key: key})
+	}
+
+	g := md.game
+	g.changeLevel(2)
+	actors := g.ecs.GetEntitiesWithComponents(components.CAITag, components.CTurnActor)
+	if len(actors) < 2 {
+		t.Fatalf("%d monsters, want at least 2", len(actors))
+	}
+	actor, _ := g.ecs.GetTurnActor(actors[0])
+	actor.AddAction(WaitAction{EntityID: actors[0]})
+	if _, err := (AttackAction{AttackerID: g.PlayerID, TargetID: actors[1]}).Execute(g); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// saveAndLoad saves the game and loads it back.
+func saveAndLoad(t *testing.T, g *Game) *Game {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadGame(&buf)
+	if err != nil {
+		t.Fatalf("LoadGame: %v", err)
+	}
+	return loaded
+}
+
+// compareGames reports the differences between the saved state of two games.
+func compareGames(t *testing.T, want, got *Game) {
+	t.Helper()
+	if got.Depth != want.Depth || got.PlayerID != want.PlayerID || got.stats != want.stats {
+		t.Errorf("depth %d, player %d, stats %+v, want %d, %d, %+v",
+			got.Depth, got.PlayerID, got.stats, want.Depth, want.PlayerID, want.stats)
+	}
+
+	ids, gotIDs := want.ecs.GetAllEntities(), got.ecs.GetAllEntities()
+	slices.Sort(ids)
+	slices.Sort(gotIDs)
+	if !slices.Equal(ids, gotIDs) {
+		t.Errorf("entities %v, want %v", gotIDs, ids)
+	}
+	for _, id := range ids {
+		compareComponents(t, id, want.ecs.EntityComponents(id), got.ecs.EntityComponents(id))
+	}
+
+	if got.rules != want.rules {
+		t.Errorf("rules %+v, want %+v", got.rules, want.rules)
+	}
+
+	if !reflect.DeepEqual(got.turnQueue, want.turnQueue) {
+		t.Errorf("turn queue %+v, want %+v", got.turnQueue, want.turnQueue)
+	}
+	if !reflect.DeepEqual(got.dungeon, want.dungeon) {
+		t.Error("dungeon differs")
+	}
+
+	if len(want.levels) == 0 || len(got.levels) != len(want.levels) {
+		t.Errorf("%d stored levels, want %d", len(got.levels), len(want.levels))
+	}
+	for depth, wl := range want.levels {
+		gl, ok := got.levels[depth]
+		if !ok {
+			t.Errorf("level %d not stored", depth)
+			continue
+		}
+		if !reflect.DeepEqual(gl.Map, wl.Map) {
+			t.Errorf("map of level %d differs", depth)
+		}
+		if len(gl.Entities) != len(wl.Entities) {
+			t.Errorf("level %d: %d entities, want %d", depth, len(gl.Entities), len(wl.Entities))
+			continue
+		}
+		for i, we := range wl.Entities {
+			ge := gl.Entities[i]
+			if ge.ID != we.ID || ge.Scheduled != we.Scheduled || ge.Delay != we.Delay {
+				t.Errorf("level %d: entity %+v, want %+v", depth, ge, we)
+				continue
+			}
+			compareComponents(t, we.ID, we.Components, ge.Components)
+		}
+	}
+	if got.log.Turn != want.log.Turn || !slices.Equal(got.log.Messages, want.log.Messages) {
+		t.Errorf("message log %+v, want %+v", got.log, want.log)
+	}
+}
+
+// compareComponents reports the differences between the components of an
+// entity.
+func compareComponents(t *testing.T, id ecs.EntityID, want, got map[components.ComponentType]any) {
+	t.Helper()
+	for ct, w := range want {
+		if c, ok := got[ct]; !ok || !sameComponent(w, c) {
+			t.Errorf("entity %d: %s component %#v, want %#v", id, ct, c, w)
+		}
+	}
+	for ct := range got {
+		if _, ok := want[ct]; !ok {
+			t.Errorf("entity %d: unexpected %s component", id, ct)
+		}
+	}
+}
+
+// sameComponent reports whether two components hold the same state. Saving
+// drops empty slices and the scratch buffers of field of views, so these are
+// compared by their saved fields.
+func sameComponent(a, b any) bool {
+	fa, ok := a.(*components.FOV)
+	if !ok {
+		return reflect.DeepEqual(a, b)
+	}
+	fb := b.(*components.FOV)
+	if fa.Range != fb.Range || !slices.Equal(fa.Visible, fb.Visible) {
+		return false
+	}
+	ca, cb := fa.GetFOVCalculator(), fb.GetFOVCalculator()
+	return slices.Equal(ca.Costs, cb.Costs) &&
+		slices.Equal(ca.ShadowCasting, cb.ShadowCasting) &&
+		slices.Equal(ca.Lighted, cb.Lighted) &&
+		slices.Equal(ca.Visibles, cb.Visibles) &&
+		slices.Equal(ca.RayCache, cb.RayCache) &&
+		ca.Rg == cb.Rg && ca.Src == cb.Src && ca.Capacity == cb.Capacity
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	g := playedGame(t)
+	loaded := saveAndLoad(t, g)
+	compareGames(t, g, loaded)
+	resaved := saveAndLoad(t, loaded)
+	compareGames(t, g, resaved)
+
+	// The games go on with the same random numbers
+	for i := range 10 {
+		want := g.rand.Int63()
+		if got := loaded.rand.Int63(); got != want {
+			t.Fatalf("draw %d of the loaded game: %d, want %d", i, got, want)
+		}
+		if got := resaved.rand.Int63(); got != want {
+			t.Fatalf("draw %d of the saved again game: %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestSavedGameKeepsItsRules(t *testing.T) {
+	rules := config.Default().Rules
+	rules.MaxMonstersPerRoom = 5
+	rules.FovRadius = 6
+	setRules(t, rules)
+
+	path := filepath.Join(t.TempDir(), config.SaveFileName)
+	md := NewModel(newTestGrid(), 1)
+	md.startGame()
+	if err := md.game.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Config.Rules = config.Default().Rules
+	md = NewModel(newTestGrid(), 2)
+	md.SaveTo(path)
+	if !md.loadSavedGame() {
+		t.Fatal("saved game not loaded")
+	}
+	if config.Config.Rules != rules {
+		t.Errorf("rules = %+v, want those of the saved game %+v", config.Config.Rules, rules)
+	}
+
+	// Saves of another map size are refused
+	config.Config.DungeonWidth = rules.DungeonWidth + 10
+	md = NewModel(newTestGrid(), 2)
+	md.SaveTo(path)
+	if md.loadSavedGame() {
+		t.Error("saved game loaded with another map size")
+	}
+}
@@ -1,14 +1,37 @@
 package game
 
 import (
-	"math/rand"
-
 	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/bestiary"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/items"
 	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
 	"github.com/sirupsen/logrus"
 )
 
+// monsterBestiary holds the monster templates used for spawning.
+var monsterBestiary = bestiary.Default()
+
+// SetBestiary sets the monster templates used by every game. It must be called
+// before any game is started.
+func SetBestiary(b *bestiary.Bestiary) {
+	monsterBestiary = b
+}
+
+// itemCatalog holds the item templates used for spawning.
+var itemCatalog = items.Default()
+
+// SetItemCatalog sets the item templates used by every game. It must be called
+// before any game is started.
+func SetItemCatalog(c *items.Catalog) {
+	itemCatalog = c
+}
+
+// playerInventorySize is the number of items the player can carry, one per
+// inventory letter.
+const playerInventorySize = 26
+
 func (g *Game) SpawnPlayer(playerStart gruid.Point) {
 	logrus.Debugf("Spawning player at %v", playerStart)
 	playerID := g.ecs.AddEntity()
@@ -21,8 +44,11 @@ func (g *Game) SpawnPlayer(playerStart gruid.Point) {
 		components.Name{Name: "Player"},
 		components.Renderable{Glyph: '@', Color: ui.ColorPlayer},
 		components.NewHealth(10),
+		components.NewInventory(playerInventorySize),
+		components.Equipment{},
+		components.CombatStats{Power: 3, Defense: 0, Accuracy: 10, Evasion: 5, CritChance: 5},
 		components.NewTurnActor(100),
-		components.NewFOVComponent(4, g.dungeon.Width, g.dungeon.Height),
+		components.NewFOVComponent(config.Config.FovRadius, g.dungeon.Width, g.dungeon.Height),
 	)
 
 	// Add to turn queue
@@ -31,53 +57,79 @@ func (g *Game) SpawnPlayer(playerStart gruid.Point) {
 	g.spatialGrid.Add(playerID, playerStart)
 }
 
+// SpawnMonster spawns a monster picked from the bestiary for the current
+// depth. Monsters found below their shallowest depth get extra health.
 func (g *Game) SpawnMonster(pos gruid.Point) {
+	t, ok := monsterBestiary.Pick(g.rand, g.Depth)
+	if !ok {
+		logrus.Debugf("No monster can spawn at depth %d", g.Depth)
+		return
+	}
+	g.spawnMonster(pos, t)
+}
+
+// spawnMonster spawns a monster of the given kind.
+func (g *Game) spawnMonster(pos gruid.Point, t bestiary.Template) {
 	monsterID := g.ecs.AddEntity()
 
-	monsterNames := []string{"Orc", "Troll", "Goblin", "Kobold"}
-	monsterName := monsterNames[rand.Intn(len(monsterNames))]
-
-	var rune rune
-	var speed uint64
-	var color gruid.Color = ui.ColorMonster // Default monster color
-	var maxHP int
-
-	switch monsterName {
-	case "Orc":
-		rune = 'o'
-		speed = 100
-		maxHP = 1
-	case "Troll":
-		rune = 'T'
-		speed = 200
-		maxHP = 1
-	case "Goblin":
-		rune = 'g'
-		speed = 100
-		color = ui.ColorSleepingMonster // Goblins use a different color
-		maxHP = 1
-	case "Kobold":
-		rune = 'k'
-		speed = 150
-		maxHP = 1
+	maxHP := t.HP + (g.Depth-t.MinDepth)/2
+
+	state := components.AIWandering
+	if t.Behavior() == components.BehaviorSleeper {
+		state = components.AIAsleep
 	}
 
 	g.ecs.AddComponents(monsterID,
 		pos,
-		components.AITag{},
+		components.AITag{Behavior: t.Behavior()},
+		components.AIMemory{},
+		state,
 		components.BlocksMovement{},
-		components.Name{Name: monsterName},
-		components.Renderable{Glyph: rune, Color: color},
+		components.Name{Name: t.Name},
+		components.Renderable{Glyph: t.Rune(), Color: t.FgColor()},
 		components.NewHealth(maxHP),
-		components.NewFOVComponent(6, g.dungeon.Width, g.dungeon.Height),
-		components.NewTurnActor(speed),
+		t.CombatStats(),
+		components.NewFOVComponent(t.FOVRange, g.dungeon.Width, g.dungeon.Height),
+		components.NewTurnActor(t.Speed),
 	)
 
-	logrus.Debugf("Created monster ID=%d at position %v, adding to turn queue at time %d",
-		monsterID, pos, g.turnQueue.CurrentTime+100)
+	logrus.Debugf("Created %s ID=%d at position %v, adding to turn queue at time %d",
+		t.Name, monsterID, pos, g.turnQueue.CurrentTime+100)
 
 	// Add to turn queue
 	g.turnQueue.Add(monsterID, g.turnQueue.CurrentTime+100)
 	// Add to spatial grid
 	g.spatialGrid.Add(monsterID, pos)
 }
+
+// SpawnItem spawns an item picked from the catalog for the current depth.
+// Items lie on the floor and do not block movement, so they are not tracked by
+// the spatial grid.
+func (g *Game) SpawnItem(pos gruid.Point) {
+	t, ok := itemCatalog.Pick(g.rand, g.Depth)
+	if !ok {
+		logrus.Debugf("No item can spawn at depth %d", g.Depth)
+		return
+	}
+	g.spawnItem(pos, t)
+}
+
+// spawnItem spawns an item of the given kind.
+func (g *Game) spawnItem(pos gruid.Point, t items.Template) {
+	itemID := g.ecs.AddEntity()
+
+	g.ecs.AddComponents(itemID,
+		pos,
+		components.Item{Template: t.Name},
+		components.Name{Name: t.Name},
+		components.Renderable{Glyph: t.Rune(), Color: t.FgColor()},
+	)
+	if t.Consumable() {
+		g.ecs.AddComponents(itemID, components.Consumable{Effect: t.ItemEffect(), Power: t.Power})
+	}
+	if t.Equippable() {
+		g.ecs.AddComponents(itemID, components.Equippable{Slot: t.EquipSlot(), Bonus: t.CombatBonus()})
+	}
+
+	logrus.Debugf("Created %s ID=%d at position %v", t.Name, itemID, pos)
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/utils"
+)
+
+// targeting holds the state of the targeting mode, where the player chooses a
+// target with a cursor. In look mode, the cursor only examines the map.
+type targeting struct {
+	prompt  string
+	look    bool
+	cursor  gruid.Point
+	targets []ecs.EntityID // visible monsters, nearest first
+	current int            // index in targets of the last focused monster
+
+	// confirm receives the chosen target: where a projectile thrown at the
+	// cursor stops.
+	confirm func(target gruid.Point) gruid.Effect
+}
+
+// startTargeting enters targeting mode with the cursor on the nearest visible
+// monster. Once the player confirms a target, confirm is called with it.
+func (md *Model) startTargeting(prompt string, confirm func(target gruid.Point) gruid.Effect) {
+	g := md.game
+	t := &targeting{prompt: prompt, confirm: confirm}
+	t.cursor, _ = g.ecs.GetPosition(g.PlayerID)
+	if fov, ok := g.ecs.GetFOV(g.PlayerID); ok {
+		t.targets = g.visibleMonsters(g.PlayerID, fov)
+	}
+	if len(t.targets) > 0 {
+		t.cursor, _ = g.ecs.GetPosition(t.targets[0])
+	}
+	md.targeting = t
+	md.mode = modeTargeting
+}
+
+// stopTargeting leaves targeting mode.
+func (md *Model) stopTargeting() {
+	md.targeting = nil
+	md.mode = modeNormal
+}
+
+// updateTargeting handles input in targeting mode: keys move the cursor or
+// cycle through visible monsters, and the mouse points at the target.
+func (md *Model) updateTargeting(msg gruid.Msg) gruid.Effect {
+	t := md.targeting
+	switch msg := msg.(type) {
+	case gruid.MsgKeyDown:
+		switch action := keymap.action(keymapTargeting, msg.Key); action {
+		case ActionCancel:
+			md.stopTargeting()
+		case ActionLook:
+			if t.look {
+				md.stopTargeting()
+			}
+		case ActionConfirm:
+			if t.look {
+				md.stopTargeting()
+				return nil
+			}
+			return md.confirmTarget()
+		case ActionNextTarget:
+			if msg.Mod&gruid.ModShift != 0 {
+				md.cycleTarget(-1)
+			} else {
+				md.cycleTarget(1)
+			}
+		default:
+			if dir := keyToDir(action); dir != (gruid.Point{}) {
+				md.moveCursor(t.cursor.Add(dir))
+			}
+		}
+	case gruid.MsgMouse:
+		if !msg.P.In(md.viewport.Bounds()) {
+			return nil
+		}
+		p := msg.P.Sub(md.viewport.Bounds().Min)
+		switch msg.Action {
+		case gruid.MouseMove:
+			md.moveCursor(p)
+		case gruid.MouseMain:
+			md.moveCursor(p)
+			if t.look {
+				return nil
+			}
+			return md.confirmTarget()
+		}
+	}
+	return nil
+}
+
+// moveCursor moves the targeting cursor, staying on the map.
+func (md *Model) moveCursor(p gruid.Point) {
+	if md.game.dungeon.InBounds(p) {
+		md.targeting.cursor = p
+	}
+}
+
+// cycleTarget moves the cursor to the next visible monster, or the previous
+// one for a negative step.
+func (md *Model) cycleTarget(step int) {
+	t := md.targeting
+	if len(t.targets) == 0 {
+		return
+	}
+	t.current = (t.current + step + len(t.targets)) % len(t.targets)
+	t.cursor, _ = md.game.ecs.GetPosition(t.targets[t.current])
+}
+
+// confirmTarget ends targeting with the target under the cursor, if the
+// player can see it.
+func (md *Model) confirmTarget() gruid.Effect {
+	g := md.game
+	t := md.targeting
+	if fov, ok := g.ecs.GetFOV(g.PlayerID); !ok || !fov.IsVisible(t.cursor, g.dungeon.Width) {
+		g.log.AddMessagef(ui.ColorUIText, "You cannot see there.")
+		return nil
+	}
+	path := g.projectilePath(t.cursor)
+	if len(path) == 0 {
+		g.log.AddMessagef(ui.ColorUIText, "You need to choose a target.")
+		return nil
+	}
+
+	md.stopTargeting()
+	return t.confirm(path[len(path)-1])
+}
+
+// projectilePath returns the path of a projectile thrown by the player at the
+// given target, without the player's position. The path stops before walls and
+// at the first entity blocking its way.
+func (g *Game) projectilePath(target gruid.Point) []gruid.Point {
+	from, _ := g.ecs.GetPosition(g.PlayerID)
+	line := utils.Line(from, target)[1:]
+	for i, p := range line {
+		if g.dungeon.IsOpaque(p) {
+			return line[:i]
+		}
+		if len(g.spatialGrid.GetEntitiesAt(p)) > 0 {
+			return line[:i+1]
+		}
+	}
+	return line
+}
+
+// drawTargeting draws the projectile path and the cursor over the map, and the
+// targeting prompt in place of the visible monsters list.
+func (md *Model) drawTargeting() {
+	g := md.game
+	t := md.targeting
+
+	if !t.look {
+		for _, p := range g.projectilePath(t.cursor) {
+			c := md.viewport.At(p)
+			c.Style.Fg = ui.ColorTargetPath
+			if c.Rune == ' ' {
+				c.Rune = '*'
+			}
+			md.viewport.Set(p, c)
+		}
+	}
+	c := md.viewport.At(t.cursor)
+	c.Style.Fg = ui.ColorTargetCursor
+	c.Style.Attrs |= ui.AttrReverse
+	md.viewport.Set(t.cursor, c)
+
+	rg := md.hud.Range()
+	if rg.Size().Y < 2 {
+		return
+	}
+	line := md.hud.Slice(rg.Line(1))
+	line.Fill(gruid.Cell{Rune: ' '})
+	x := drawText(line, 0, t.prompt+" ", gruid.Style{Fg: ui.ColorUIHighlight})
+	x = drawText(line, x, g.describeTile(t.cursor), gruid.Style{Fg: ui.ColorUIText})
+
+	// Key help only when there is room left for it
+	help := "  " + joinHints(
+		keyHint(keymapTargeting, ActionNextTarget, "next"),
+		keyHint(keymapTargeting, ActionConfirm, "confirm"),
+		keyHint(keymapTargeting, ActionCancel, "cancel"))
+	if t.look {
+		help = "  " + joinHints(
+			keyHint(keymapTargeting, ActionNextTarget, "next"),
+			keyHint(keymapTargeting, ActionCancel, "done"))
+	}
+	if x+len(help) <= rg.Size().X {
+		drawText(line, x, help, gruid.Style{Fg: ui.ColorUIBorder})
+	}
+}
@@ -0,0 +1,178 @@
+package game
+
+import (
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// autoKind is the kind of a sequence of automatic player actions.
+type autoKind int
+
+// Kinds of automatic player action sequences. Travel queues all its moves at
+// once, while the others plan one action at a time.
+const (
+	autoTravel autoKind = iota
+	autoExplore
+	autoRun
+	autoRest
+)
+
+// autoMove watches a sequence of actions queued by the player at once, such
+// as travel or auto-exploration, so that it stops when something deserves the
+// player's attention.
+type autoMove struct {
+	Active   bool
+	Kind     autoKind
+	From     gruid.Point           // player position when the sequence started
+	Seen     map[ecs.EntityID]bool // monsters in view when the sequence started
+	LogCount int                   // messages logged when last checked
+	HP       int                   // player health when last checked
+	Run      running               // state of a run
+}
+
+// startAutoMove starts watching the player actions queued from now on.
+func (g *Game) startAutoMove(kind autoKind) {
+	g.auto = autoMove{Active: true, Kind: kind, Seen: make(map[ecs.EntityID]bool), LogCount: g.log.Count}
+	g.auto.From, _ = g.ecs.GetPosition(g.PlayerID)
+	if fov, ok := g.ecs.GetFOV(g.PlayerID); ok {
+		for _, id := range g.visibleMonsters(g.PlayerID, fov) {
+			g.auto.Seen[id] = true
+		}
+	}
+	if health, ok := g.ecs.GetHealth(g.PlayerID); ok {
+		g.auto.HP = health.CurrentHP
+	}
+}
+
+// stopAutoMove stops watching queued player actions.
+func (g *Game) stopAutoMove() {
+	g.auto = autoMove{}
+}
+
+// autoAction returns the next action planned by the current sequence of
+// automatic player actions, or nil if it is over.
+func (g *Game) autoAction() GameAction {
+	switch g.auto.Kind {
+	case autoExplore:
+		return g.exploreAction()
+	case autoRun:
+		return g.runAction()
+	case autoRest:
+		return g.restAction()
+	}
+	return nil
+}
+
+// autoMoveInterrupted reports whether the queued player actions should be
+// cancelled before performing the next one: a new monster came into view, a
+// message was logged during travel, the player lost health, or the next step
+// is blocked. Messages do not stop auto-exploration, which logs its own
+// pickups.
+func (g *Game) autoMoveInterrupted(next any) bool {
+	if next == nil {
+		return false
+	}
+	if fov, ok := g.ecs.GetFOV(g.PlayerID); ok {
+		for _, id := range g.visibleMonsters(g.PlayerID, fov) {
+			if !g.auto.Seen[id] {
+				return true
+			}
+		}
+	}
+	if g.auto.Kind != autoExplore && g.log.Count != g.auto.LogCount {
+		return true
+	}
+	if health, ok := g.ecs.GetHealth(g.PlayerID); ok {
+		if health.CurrentHP < g.auto.HP {
+			return true
+		}
+		g.auto.HP = health.CurrentHP
+	}
+	if move, ok := next.(MoveAction); ok {
+		pos, _ := g.ecs.GetPosition(g.PlayerID)
+		if len(g.spatialGrid.GetEntitiesAt(pos.Add(move.Direction))) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// travelPather implements paths.Astar for player travel over explored
+// walkable tiles, in the eight directions.
+type travelPather struct {
+	g  *Game
+	nb paths.Neighbors
+}
+
+// Neighbors implements paths.Pather.Neighbors.
+func (tp *travelPather) Neighbors(p gruid.Point) []gruid.Point {
+	return tp.nb.All(p, func(q gruid.Point) bool {
+		return tp.g.dungeon.isWalkable(q) && tp.g.dungeon.IsExplored(q)
+	})
+}
+
+// Cost implements paths.Dijkstra.Cost.
+func (tp *travelPather) Cost(from, to gruid.Point) int {
+	return 1
+}
+
+// Estimation implements paths.Astar.Estimation.
+func (tp *travelPather) Estimation(from, to gruid.Point) int {
+	return paths.DistanceChebyshev(from, to)
+}
+
+// travelPath returns the path from the player to the given explored position
+// over known walkable tiles, without the player's position. It returns nil if
+// there is no known way there.
+func (g *Game) travelPath(to gruid.Point) []gruid.Point {
+	from, _ := g.ecs.GetPosition(g.PlayerID)
+	if from == to || !g.dungeon.InBounds(to) || !g.dungeon.IsExplored(to) || !g.dungeon.isWalkable(to) {
+		return nil
+	}
+	path := g.pathRange().AstarPath(&travelPather{g: g}, from, to)
+	if len(path) < 2 {
+		return nil
+	}
+	return path[1:]
+}
+
+// travelTo queues the moves taking the player to the given position, as a
+// watched sequence of actions.
+func (md *Model) travelTo(to gruid.Point) gruid.Effect {
+	g := md.game
+	path := g.travelPath(to)
+	if path == nil {
+		if from, _ := g.ecs.GetPosition(g.PlayerID); from != to && g.dungeon.IsExplored(to) {
+			g.log.AddMessagef(ui.ColorUIText, "You know no way there.")
+		}
+		return nil
+	}
+
+	actor, _ := g.ecs.GetTurnActor(g.PlayerID)
+	prev, _ := g.ecs.GetPosition(g.PlayerID)
+	for _, p := range path {
+		actor.AddAction(MoveAction{Direction: p.Sub(prev), EntityID: g.PlayerID})
+		prev = p
+	}
+	g.startAutoMove(autoTravel)
+	return md.EndTurn()
+}
+
+// drawTravelHover highlights the hovered tile and the path the player would
+// follow to travel there.
+func (md *Model) drawTravelHover() {
+	g := md.game
+	if !g.dungeon.InBounds(md.hover) || !g.dungeon.IsExplored(md.hover) {
+		return
+	}
+	for _, p := range g.travelPath(md.hover) {
+		c := md.viewport.At(p)
+		c.Style.Fg = ui.ColorTargetPath
+		md.viewport.Set(p, c)
+	}
+	c := md.viewport.At(md.hover)
+	c.Style.Attrs |= ui.AttrReverse
+	md.viewport.Set(md.hover, c)
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"testing"
+
+	"codeberg.org/anaseto/gruid"
+)
+
+// openLevel makes the level of g an explored room filling the map, with the
+// player at from.
+func openLevel(g *Game, from gruid.Point) {
+	m := g.dungeon
+	m.Grid.Fill(WallCell)
+	it := m.Grid.Iterator()
+	for it.Next() {
+		p := it.P()
+		if p.X > 0 && p.Y > 0 && p.X < m.Width-1 && p.Y < m.Height-1 {
+			it.SetCell(FloorCell)
+		}
+		m.SetExplored(p)
+	}
+	g.SpawnPlayer(from)
+}
+
+func TestTravelPathIsDiagonal(t *testing.T) {
+	md := NewModel(newTestGrid(), 1)
+	md.savePath = ""
+	g := md.game
+	g.dungeon = NewMap(g.rules.DungeonWidth, g.rules.DungeonHeight)
+	from, to := gruid.Point{X: 2, Y: 2}, gruid.Point{X: 7, Y: 7}
+	openLevel(g, from)
+
+	path := g.travelPath(to)
+	if len(path) != 5 {
+		t.Fatalf("path %v, want 5 diagonal steps", path)
+	}
+	prev := from
+	for _, p := range path {
+		if d := p.Sub(prev); d != (gruid.Point{X: 1, Y: 1}) {
+			t.Errorf("step %v from %v, want a diagonal one", d, prev)
+		}
+		prev = p
+	}
+
+	md.processTurnQueue()
+	md.travelTo(to)
+	if pos, _ := g.ecs.GetPosition(g.PlayerID); pos != to {
+		t.Errorf("player at %v after traveling, want %v", pos, to)
+	}
+	if g.stats.Turns != len(path) {
+		t.Errorf("travel took %d turns, want %d", g.stats.Turns, len(path))
+	}
+}
+
+func TestExploreMessages(t *testing.T) {
+	md := NewModel(newTestGrid(), 1)
+	md.savePath = ""
+	g := md.game
+	g.dungeon = NewMap(g.rules.DungeonWidth, g.rules.DungeonHeight)
+	openLevel(g, gruid.Point{X: 2, Y: 2})
+	lastMessage := func() string {
+		return g.log.Messages[len(g.log.Messages)-1].Text
+	}
+
+	if a := g.exploreAction(); a != nil {
+		t.Errorf("explore action %+v on an explored level", a)
+	}
+	if got, want := lastMessage(), "There is nothing left to explore here."; got != want {
+		t.Errorf("message %q, want %q", got, want)
+	}
+
+	// A wall splits the level, with an unexplored part behind it
+	m := g.dungeon
+	m.Explored = make([]uint64, len(m.Explored))
+	it := m.Grid.Iterator()
+	for it.Next() {
+		p := it.P()
+		if p.X == 10 {
+			it.SetCell(WallCell)
+		}
+		if p.X <= 11 {
+			m.SetExplored(p)
+		}
+	}
+	if a := g.exploreAction(); a != nil {
+		t.Errorf("explore action %+v toward an unreachable room", a)
+	}
+	if got, want := lastMessage(), "You can't reach the unexplored areas."; got != want {
+		t.Errorf("message %q, want %q", got, want)
+	}
+}
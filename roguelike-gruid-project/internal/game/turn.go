@@ -1,6 +1,7 @@
 package game
 
 import (
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,10 +23,17 @@ func (md *Model) processTurnQueue() {
 
 	g.turnQueue.PrintQueue()
 
-	// Process turns until we need player input or run out of actors
-	for i := range 100 { // Limit iterations to prevent infinite loops
+	// Process turns until we need player input or run out of actors. The
+	// iteration limit, preventing infinite loops, applies between two player
+	// actions, as the player may have queued a long sequence of them.
+	for i := 0; i < 100; i++ {
 		logrus.Debugf("Turn queue iteration %d", i)
 
+		if g.state == StateGameOver {
+			logrus.Debug("========= processTurnQueue ended (game over) =========")
+			return
+		}
+
 		if g.turnQueue.IsEmpty() {
 			logrus.Debug("Turn queue is empty.")
 			logrus.Debug("========= processTurnQueue ended (queue empty) =========")
@@ -52,9 +60,19 @@ func (md *Model) processTurnQueue() {
 		}
 
 		isPlayer := turnEntry.EntityID == g.PlayerID
+		if isPlayer && g.auto.Active && actor.PeekNextAction() == nil {
+			if action := g.autoAction(); action != nil {
+				actor.AddAction(action)
+			}
+		}
+		if isPlayer && g.auto.Active && g.autoMoveInterrupted(actor.PeekNextAction()) {
+			logrus.Debug("Queued player actions interrupted.")
+			actor.ClearActions()
+		}
 		action := actor.NextAction()
 
 		if isPlayer && action == nil {
+			g.stopAutoMove()
 			g.waitingForInput = true
 			logrus.Debug("It's the player's turn, waiting for input.")
 			g.turnQueue.Add(turnEntry.EntityID, turnEntry.Time)
@@ -62,6 +80,11 @@ func (md *Model) processTurnQueue() {
 			return
 		}
 
+		if action == nil && g.ecs.HasComponent(turnEntry.EntityID, components.CAITag) {
+			// Monsters decide what to do when their turn comes
+			action = g.monsterAction(turnEntry.EntityID)
+		}
+
 		if action == nil {
 			logrus.Debugf("Entity %d has no actions, rescheduling turn at time %d", turnEntry.EntityID, turnEntry.Time)
 			g.turnQueue.Add(turnEntry.EntityID, turnEntry.Time)
@@ -85,6 +108,12 @@ func (md *Model) processTurnQueue() {
 
 		logrus.Debugf("Action executed for entity %d, cost: %d", turnEntry.EntityID, cost)
 
+		if isPlayer && cost > 0 {
+			g.stats.Turns++
+			g.regenerate()
+			i = 0
+		}
+
 		// Update the game time and schedule next turn
 		g.turnQueue.CurrentTime = turnEntry.Time + uint64(cost)
 		g.turnQueue.Add(turnEntry.EntityID, g.turnQueue.CurrentTime)
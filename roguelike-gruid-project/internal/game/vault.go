@@ -0,0 +1,258 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"codeberg.org/anaseto/gruid"
+	"codeberg.org/anaseto/gruid/paths"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/vaults"
+	"github.com/sirupsen/logrus"
+)
+
+// vaultLibrary holds the prefab vaults stamped into levels.
+var vaultLibrary = vaults.Default()
+
+// SetVaults sets the prefab vaults used by every game. It must be called
+// before any game is started.
+func SetVaults(l *vaults.Library) {
+	vaultLibrary = l
+}
+
+// vaultPlacementTries is the number of random positions considered for a
+// vault, the one covering the least floor being chosen.
+const vaultPlacementTries = 50
+
+// placeVaults stamps up to MaxVaults vaults for the current depth into the
+// level, each turned and flipped at random, away from the player start and
+// the stairs. Vaults are put where they cover the least floor, preferably in
+// solid rock, and the level is joined again around them if needed. It
+// returns the regions without the tiles covered by vaults, and the floor of
+// each vault as a region.
+func (m *Map) placeVaults(g *Game, regions []Region, playerStart gruid.Point) ([]Region, []Region) {
+	var vaultRegions []Region
+	vault := make([]bool, m.Width*m.Height)
+	inVault := func(p gruid.Point) bool {
+		return m.InBounds(p) && vault[p.Y*m.Width+p.X]
+	}
+	keep := []gruid.Point{playerStart, m.StairsDown}
+	for range config.Config.MaxVaults {
+		t, ok := vaultLibrary.Pick(g.rand, g.Depth)
+		if !ok {
+			logrus.Debugf("No vault can appear at depth %d", g.Depth)
+			break
+		}
+		for range g.rand.Intn(4) {
+			t = t.Rotate()
+		}
+		if g.rand.Intn(2) == 0 {
+			t = t.Mirror()
+		}
+		at, ok := m.vaultSpace(g.rand, t.Size, keep, inVault)
+		if !ok {
+			logrus.Debugf("No room for vault %s", t.Name)
+			continue
+		}
+		logrus.Debugf("Placing vault %s at %v", t.Name, at)
+		vaultRegions = append(vaultRegions, m.stampVault(g, t, at, vault))
+	}
+	if len(vaultRegions) == 0 {
+		return regions, nil
+	}
+
+	// Vaults may have cut the level apart: tunnels join the parts outside of
+	// vaults, those inside being reached through their entrances
+	parts, largest := walkableParts(m)
+	joined := parts[largest].without(inVault)
+	for i, part := range parts {
+		part = part.without(inVault)
+		if i != largest && len(part) > 0 && len(joined) > 0 {
+			m.dig(part[0], joined.closestTo(part[0]), inVault)
+			joined = append(joined, part...)
+		}
+	}
+
+	var kept []Region
+	for i, region := range regions {
+		reg := region.without(inVault)
+		// The first and last regions hold the player start and the stairs
+		if len(reg) > 0 || i == 0 || i == len(regions)-1 {
+			kept = append(kept, reg)
+		}
+	}
+	return kept, vaultRegions
+}
+
+// vaultSpace returns the position, among random ones, where a vault of the
+// given size covers the least floor. The vault stays two tiles off the map
+// edges, so that tunnels can go around it, and neither it nor the tiles
+// around it cover the kept positions or other vaults.
+func (m *Map) vaultSpace(rng *rand.Rand, size gruid.Point, keep []gruid.Point, inVault func(gruid.Point) bool) (gruid.Point, bool) {
+	w, h := m.Width-3-size.X, m.Height-3-size.Y
+	if w <= 0 || h <= 0 {
+		return gruid.Point{}, false
+	}
+	best, bestFloor := gruid.Point{}, -1
+	for range vaultPlacementTries {
+		at := gruid.Point{X: 2 + rng.Intn(w), Y: 2 + rng.Intn(h)}
+		rg := gruid.NewRange(at.X-1, at.Y-1, at.X+size.X+1, at.Y+size.Y+1)
+		if slices.ContainsFunc(keep, func(p gruid.Point) bool { return p.In(rg) }) {
+			continue
+		}
+		floor, free := 0, true
+		for p := range points(rg) {
+			if inVault(p) {
+				free = false
+				break
+			}
+			if m.isWalkable(p) {
+				floor++
+			}
+		}
+		if free && (bestFloor < 0 || floor < bestFloor) {
+			best, bestFloor = at, floor
+		}
+		if bestFloor == 0 {
+			break
+		}
+	}
+	return best, bestFloor >= 0
+}
+
+// points iterates over the positions of a range, row by row.
+func points(rg gruid.Range) func(func(gruid.Point) bool) {
+	return func(yield func(gruid.Point) bool) {
+		for y := rg.Min.Y; y < rg.Max.Y; y++ {
+			for x := rg.Min.X; x < rg.Max.X; x++ {
+				if !yield(gruid.Point{X: x, Y: y}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// stampVault draws the vault with its top-left corner at the given position,
+// marking its tiles in vault, spawns its monsters and items, and joins its
+// entrances to the level. It returns the floor of the vault.
+func (m *Map) stampVault(g *Game, t vaults.Template, at gruid.Point, vault []bool) Region {
+	var region Region
+	var entrances []gruid.Point
+	for q := range points(gruid.NewRange(0, 0, t.Size.X, t.Size.Y)) {
+		p := at.Add(q)
+		cell := t.At(q)
+		switch cell.Terrain {
+		case vaults.Outside:
+			continue
+		case vaults.Wall:
+			m.Grid.Set(p, WallCell)
+			vault[p.Y*m.Width+p.X] = true
+			continue
+		case vaults.Entrance:
+			entrances = append(entrances, p)
+		}
+		m.Grid.Set(p, FloorCell)
+		vault[p.Y*m.Width+p.X] = true
+		region = append(region, p)
+		g.spawnVaultCell(p, cell)
+	}
+
+	inVault := func(p gruid.Point) bool {
+		return m.InBounds(p) && vault[p.Y*m.Width+p.X]
+	}
+	for _, e := range entrances {
+		// Join the closest walkable tile outside of vaults
+		var outside Region
+		it := m.Grid.Iterator()
+		for it.Next() {
+			if m.isWalkable(it.P()) && !inVault(it.P()) {
+				outside = append(outside, it.P())
+			}
+		}
+		if len(outside) > 0 {
+			m.dig(e, outside.closestTo(e), inVault)
+		}
+	}
+	return region
+}
+
+// without returns the positions of the region for which the function returns
+// false.
+func (reg Region) without(f func(gruid.Point) bool) Region {
+	var r Region
+	for _, p := range reg {
+		if !f(p) {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+// spawnVaultCell spawns the monster and item of a vault cell, if any. Names
+// missing from the bestiary or item catalog are skipped with a warning, as
+// they may have been removed by the player's data files.
+func (g *Game) spawnVaultCell(p gruid.Point, cell vaults.Cell) {
+	switch cell.Monster {
+	case "":
+	case vaults.Random:
+		g.SpawnMonster(p)
+	default:
+		if t, ok := monsterBestiary.Get(cell.Monster); ok {
+			g.spawnMonster(p, t)
+		} else {
+			logrus.Warnf("Vault monster %q is not in the bestiary", cell.Monster)
+		}
+	}
+	switch cell.Item {
+	case "":
+	case vaults.Random:
+		g.SpawnItem(p)
+	default:
+		if t, ok := itemCatalog.Get(cell.Item); ok {
+			g.spawnItem(p, t)
+		} else {
+			logrus.Warnf("Vault item %q is not in the item catalog", cell.Item)
+		}
+	}
+}
+
+// tunnelPather implements paths.Astar for digging tunnels around vaults,
+// away from the map edges.
+type tunnelPather struct {
+	m       *Map
+	inVault func(gruid.Point) bool
+	nb      paths.Neighbors
+}
+
+// Neighbors implements paths.Pather.Neighbors.
+func (tp *tunnelPather) Neighbors(p gruid.Point) []gruid.Point {
+	return tp.nb.Cardinal(p, func(q gruid.Point) bool {
+		return q.X > 0 && q.Y > 0 && q.X < tp.m.Width-1 && q.Y < tp.m.Height-1 && !tp.inVault(q)
+	})
+}
+
+// Cost implements paths.Dijkstra.Cost.
+func (tp *tunnelPather) Cost(from, to gruid.Point) int {
+	return 1
+}
+
+// Estimation implements paths.Astar.Estimation.
+func (tp *tunnelPather) Estimation(from, to gruid.Point) int {
+	return paths.DistanceManhattan(from, to)
+}
+
+// dig carves a tunnel between two points, going around vaults.
+func (m *Map) dig(from, to gruid.Point, inVault func(gruid.Point) bool) {
+	pr := paths.NewPathRange(m.Grid.Bounds())
+	path := pr.AstarPath(&tunnelPather{m: m, inVault: inVault}, from, to)
+	if path == nil {
+		logrus.Debugf("No tunnel from %v to %v around vaults", from, to)
+		return
+	}
+	for _, p := range path {
+		if m.Grid.At(p) == WallCell {
+			m.Grid.Set(p, FloorCell)
+		}
+	}
+}
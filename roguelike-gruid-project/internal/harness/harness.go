@@ -0,0 +1,157 @@
+// Package harness drives a game model without any screen, for scripted tests.
+// Input messages are fed synchronously to the model, and each drawn grid is
+// flushed to a headless driver whose screen can be compared against golden
+// ASCII snapshots.
+package harness
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/game"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to a non-empty
+// value, makes AssertSnapshot rewrite golden files instead of comparing.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenDir is the directory, relative to the test package, holding golden
+// files.
+const GoldenDir = "testdata"
+
+// TB is the part of testing.TB used by the harness.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Harness runs a game model headlessly. The model neither saves nor records.
+type Harness struct {
+	tb     TB
+	grid   gruid.Grid
+	Model  *game.Model
+	Driver *ui.Headless
+}
+
+// New boots a new game with the given seed, and runs it until the player's
+// first turn.
+func New(tb TB, seed int64) *Harness {
+	tb.Helper()
+	grid := gruid.NewGrid(config.Config.UIWidth(), config.Config.UIHeight())
+	h := &Harness{
+		tb:     tb,
+		grid:   grid,
+		Model:  game.NewModel(grid, seed),
+		Driver: ui.NewHeadless(config.Config.UIWidth(), config.Config.UIHeight()),
+	}
+	h.Send(gruid.MsgInit{})
+	return h
+}
+
+// Game returns the game driven by the model.
+func (h *Harness) Game() *game.Game {
+	return h.Model.Game()
+}
+
+// Send feeds messages to the model, drawing after each one like the gruid
+// application loop does. Effects returned by the model are not run.
+func (h *Harness) Send(msgs ...gruid.Msg) {
+	h.tb.Helper()
+	for _, msg := range msgs {
+		if h.Model.Ended() {
+			h.tb.Fatalf("message %#v sent after the model ended", msg)
+		}
+		h.Model.Update(msg)
+		h.flush(h.Model.Draw())
+	}
+}
+
+// Keys sends a key down message for each key.
+func (h *Harness) Keys(keys ...gruid.Key) {
+	h.tb.Helper()
+	for _, k := range keys {
+		h.Send(gruid.MsgKeyDown{Key: k})
+	}
+}
+
+// Click sends a mouse message with the given action at the given position.
+func (h *Harness) Click(action gruid.MouseAction, p gruid.Point) {
+	h.tb.Helper()
+	h.Send(gruid.MsgMouse{Action: action, P: p})
+}
+
+// Advance runs turns until the player has to act, and redraws.
+func (h *Harness) Advance() {
+	h.Model.AdvanceTurns()
+	h.flush(h.Model.Draw())
+}
+
+// Ended reports whether the model asked the application to end.
+func (h *Harness) Ended() bool {
+	return h.Model.Ended()
+}
+
+// Snapshot returns the current screen as ASCII text.
+func (h *Harness) Snapshot() string {
+	return h.Driver.String()
+}
+
+// AssertSnapshot compares the current screen with the golden file
+// testdata/<name>.golden, or rewrites the file if UPDATE_GOLDEN is set.
+func (h *Harness) AssertSnapshot(name string) {
+	h.tb.Helper()
+	got := h.Snapshot()
+	path := filepath.Join(GoldenDir, name+".golden")
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(GoldenDir, 0o755); err != nil {
+			h.tb.Fatalf("%v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			h.tb.Fatalf("%v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.tb.Fatalf("reading golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	if got != string(want) {
+		h.tb.Errorf("screen does not match %s:\n%s", path, diff(string(want), got))
+	}
+}
+
+// flush sends the whole drawn grid to the headless driver.
+func (h *Harness) flush(gd gruid.Grid) {
+	size := h.grid.Size()
+	frame := gruid.Frame{Width: size.X, Height: size.Y}
+	gd.Iter(func(p gruid.Point, c gruid.Cell) {
+		frame.Cells = append(frame.Cells, gruid.FrameCell{Cell: c, P: p.Add(gd.Bounds().Min)})
+	})
+	h.Driver.Flush(frame)
+}
+
+// diff returns the lines that differ between want and got.
+func diff(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			b.WriteString("-" + w + "\n+" + g + "\n")
+		}
+	}
+	return b.String()
+}
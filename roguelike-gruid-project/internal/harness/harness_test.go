@@ -0,0 +1,119 @@
+package harness_test
+
+import (
+	"strings"
+	"testing"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/config"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/harness"
+)
+
+const seed = 1
+
+// directions are the keys moving the player in each cardinal direction.
+var directions = []struct {
+	key   gruid.Key
+	delta gruid.Point
+}{
+	{"l", gruid.Point{X: 1}},
+	{"h", gruid.Point{X: -1}},
+	{"j", gruid.Point{Y: 1}},
+	{"k", gruid.Point{Y: -1}},
+}
+
+// useDefaultConfig runs the test with the default configuration, which the
+// golden files were made with.
+func useDefaultConfig(t *testing.T) {
+	saved := config.Config
+	t.Cleanup(func() { config.Config = saved })
+	config.Config = config.Default()
+}
+
+func playerPos(t *testing.T, h *harness.Harness) gruid.Point {
+	t.Helper()
+	g := h.Game()
+	pos, ok := g.ECS().GetPosition(g.PlayerID)
+	if !ok {
+		t.Fatal("player has no position")
+	}
+	return pos
+}
+
+// freeDirection returns the first direction in which the player can step.
+func freeDirection(t *testing.T, h *harness.Harness) (gruid.Key, gruid.Point) {
+	t.Helper()
+	g := h.Game()
+	pos := playerPos(t, h)
+	for _, d := range directions {
+		p := pos.Add(d.delta)
+		if g.Dungeon().InBounds(p) && !g.Dungeon().IsWall(p) && len(g.ECS().EntitiesAt(p)) == 0 {
+			return d.key, d.delta
+		}
+	}
+	t.Fatal("the player cannot move")
+	return "", gruid.Point{}
+}
+
+func TestMovement(t *testing.T) {
+	useDefaultConfig(t)
+	h := harness.New(t, seed)
+	h.AssertSnapshot("start")
+
+	start := playerPos(t, h)
+	key, delta := freeDirection(t, h)
+	h.Keys(key)
+	if got, want := playerPos(t, h), start.Add(delta); got != want {
+		t.Errorf("player at %v after %q, want %v", got, key, want)
+	}
+	h.AssertSnapshot("move")
+}
+
+func TestBumpAttack(t *testing.T) {
+	useDefaultConfig(t)
+	h := harness.New(t, seed)
+
+	key, delta := freeDirection(t, h)
+	start := playerPos(t, h)
+	h.Game().SpawnMonster(start.Add(delta))
+	h.Keys(key)
+	if got := playerPos(t, h); got != start {
+		t.Errorf("player moved to %v instead of attacking", got)
+	}
+	if !strings.Contains(h.Snapshot(), "Player attacks") {
+		t.Errorf("no attack message:\n%s", h.Snapshot())
+	}
+	h.AssertSnapshot("bump-attack")
+}
+
+func TestFieldOfView(t *testing.T) {
+	useDefaultConfig(t)
+	const radius = 3
+	config.Config.FovRadius = radius
+	h := harness.New(t, seed)
+
+	pos := playerPos(t, h)
+	m := h.Game().Dungeon()
+	for y := range m.Height {
+		for x := range m.Width {
+			p := gruid.Point{X: x, Y: y}
+			d := p.Sub(pos)
+			if m.IsExplored(p) && d.X*d.X+d.Y*d.Y > radius*radius {
+				t.Errorf("%v explored, beyond %d tiles of the player at %v", p, radius, pos)
+			}
+		}
+	}
+	h.AssertSnapshot("fov-radius-3")
+}
+
+func TestQuitEnds(t *testing.T) {
+	useDefaultConfig(t)
+	h := harness.New(t, seed)
+	if h.Ended() {
+		t.Fatal("ended before quitting")
+	}
+	h.Keys("q")
+	if !h.Ended() {
+		t.Error("not ended after quitting")
+	}
+}
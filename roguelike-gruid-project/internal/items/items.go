@@ -0,0 +1,167 @@
+// Package items defines the item templates used to spawn items, consumables
+// and equipment alike. Like the bestiary, a default catalog is embedded in the
+// binary, and players can override or extend it with their own data file.
+package items
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ecs/components"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/spawntable"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/ui"
+)
+
+//go:embed items.json
+var defaultData []byte
+
+// Template describes a kind of item.
+type Template struct {
+	Name   string `json:"name"`
+	Glyph  string `json:"glyph"`  // a single character
+	Color  string `json:"color"`  // a color name known by ui.ColorByName
+	Effect string `json:"effect"` // effect when used, none if empty
+	Power  int    `json:"power"`  // strength of the effect
+	Slot   string `json:"slot"`   // equipment slot, not equippable if empty
+	Bonus  Bonus  `json:"bonus"`  // combat bonuses while equipped
+	spawntable.Spawn
+}
+
+// Bonus holds the combat stats added by an equipped item.
+type Bonus struct {
+	Power      int `json:"power"`
+	Defense    int `json:"defense"`
+	Accuracy   int `json:"accuracy"`
+	Evasion    int `json:"evasion"`
+	CritChance int `json:"crit_chance"`
+}
+
+// Key returns the name of the item.
+func (t Template) Key() string {
+	return t.Name
+}
+
+// Rune returns the glyph of the item.
+func (t Template) Rune() rune {
+	r, _ := utf8.DecodeRuneInString(t.Glyph)
+	return r
+}
+
+// FgColor returns the color of the item glyph.
+func (t Template) FgColor() gruid.Color {
+	c, _ := ui.ColorByName(t.Color)
+	return c
+}
+
+// ItemEffect returns the effect of the item when used.
+func (t Template) ItemEffect() components.ItemEffect {
+	return components.ItemEffect(t.Effect)
+}
+
+// Consumable reports whether the item is used up for an effect.
+func (t Template) Consumable() bool {
+	return t.Effect != ""
+}
+
+// Equippable reports whether the item can be worn or wielded.
+func (t Template) Equippable() bool {
+	return t.Slot != ""
+}
+
+// EquipSlot returns the equipment slot of the item.
+func (t Template) EquipSlot() components.EquipSlot {
+	return components.EquipSlot(t.Slot)
+}
+
+// CombatBonus returns the combat stats added by the item while equipped.
+func (t Template) CombatBonus() components.CombatStats {
+	return components.CombatStats{
+		Power:      t.Bonus.Power,
+		Defense:    t.Bonus.Defense,
+		Accuracy:   t.Bonus.Accuracy,
+		Evasion:    t.Bonus.Evasion,
+		CritChance: t.Bonus.CritChance,
+	}
+}
+
+// validate checks that the template fields have usable values.
+func (t Template) validate() error {
+	switch {
+	case t.Name == "":
+		return errors.New("missing name")
+	case utf8.RuneCountInString(t.Glyph) != 1:
+		return fmt.Errorf("%s: glyph %q is not a single character", t.Name, t.Glyph)
+	case t.Consumable() && !t.ItemEffect().Valid():
+		return fmt.Errorf("%s: unknown effect %q", t.Name, t.Effect)
+	case t.Power < 0:
+		return fmt.Errorf("%s: power must not be negative", t.Name)
+	case t.Equippable() && !t.EquipSlot().Valid():
+		return fmt.Errorf("%s: unknown slot %q", t.Name, t.Slot)
+	case t.Equippable() && t.Consumable():
+		return fmt.Errorf("%s: an item cannot be both equippable and consumable", t.Name)
+	case !t.Equippable() && t.Bonus != (Bonus{}):
+		return fmt.Errorf("%s: bonus without slot", t.Name)
+	}
+	if err := t.Spawn.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", t.Name, err)
+	}
+	if _, ok := ui.ColorByName(t.Color); !ok {
+		return fmt.Errorf("%s: unknown color %q", t.Name, t.Color)
+	}
+	return nil
+}
+
+// Catalog holds the item templates, in file order.
+type Catalog struct {
+	spawntable.Table[Template]
+}
+
+// Read reads a list of templates in JSON format.
+func Read(r io.Reader) (*Catalog, error) {
+	templates, err := readTemplates(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{spawntable.Table[Template]{Templates: templates}}, nil
+}
+
+func readTemplates(r io.Reader) ([]Template, error) {
+	var templates []Template
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&templates); err != nil {
+		return nil, fmt.Errorf("decode items: %w", err)
+	}
+	for _, t := range templates {
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	return templates, nil
+}
+
+// Default returns the item catalog embedded in the binary.
+func Default() *Catalog {
+	c, err := Read(bytes.NewReader(defaultData))
+	if err != nil {
+		panic(fmt.Sprintf("embedded items: %v", err))
+	}
+	return c
+}
+
+// Load returns the default catalog overridden by the file at path, if it
+// exists: templates with the name of a default one replace it, and others are
+// added.
+func Load(path string) (*Catalog, error) {
+	c := Default()
+	if err := c.Override(path, readTemplates); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
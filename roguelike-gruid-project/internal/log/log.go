@@ -2,6 +2,7 @@ package log
 
 import (
 	"fmt"
+	"strings"
 
 	"codeberg.org/anaseto/gruid"
 )
@@ -19,24 +20,35 @@ const (
 	logConfirm
 )
 
-type logEntry struct {
-	Text  string
-	MText string
-	Index int
-	Tick  bool
-	Style logStyle
-	Dups  int
-}
-
 // Message represents a single message with associated color.
 type Message struct {
 	Text  string
 	Color gruid.Color
+	Index int      // turn index at which the message was last added
+	Tick  bool     // message arrived during the current turn
+	Style logStyle // semantic style of the message
+	Dups  int      // number of folded consecutive duplicates
+}
+
+// String returns the message text, with a repeat counter when duplicates were
+// folded into it.
+func (m Message) String() string {
+	if m.Dups > 0 {
+		return fmt.Sprintf("%s (x%d)", m.Text, m.Dups+1)
+	}
+	return m.Text
+}
+
+// IsCritical reports whether the message should stand out from the others.
+func (m Message) IsCritical() bool {
+	return m.Style == logCritic
 }
 
 // MessageLog stores a list of game messages.
 type MessageLog struct {
 	Messages []Message
+	Turn     int // current turn index
+	Count    int // number of messages added, folded duplicates included
 	// TODO: Consider adding a max size and pruning logic if needed.
 }
 
@@ -48,8 +60,9 @@ func NewMessageLog() *MessageLog {
 }
 
 // AddMessage adds a new message with the given text and color to the log.
+// A message identical to the last one is folded into it instead.
 func (ml *MessageLog) AddMessage(text string, color gruid.Color) {
-	ml.Messages = append(ml.Messages, Message{Text: text, Color: color})
+	ml.add(Message{Text: text, Color: color, Style: logNormal})
 	// TODO: Pruning logic if max size is implemented.
 }
 
@@ -59,11 +72,61 @@ func (ml *MessageLog) AddMessagef(color gruid.Color, format string, args ...inte
 	ml.AddMessage(text, color)
 }
 
-// // Optional: Method to retrieve messages (e.g., for UI rendering)
-// func (ml *MessageLog) GetMessages(count int) []Message {
-//  start := len(ml.Messages) - count
-//  if start < 0 {
-//      start = 0
-//  }
-//  return ml.Messages[start:]
-// }
+// AddCriticalf adds a new formatted message that should stand out from the
+// rest of the log, like the death of the player.
+func (ml *MessageLog) AddCriticalf(color gruid.Color, format string, args ...interface{}) {
+	ml.add(Message{Text: fmt.Sprintf(format, args...), Color: color, Style: logCritic})
+}
+
+func (ml *MessageLog) add(msg Message) {
+	msg.Index = ml.Turn
+	msg.Tick = true
+	ml.Count++
+	if n := len(ml.Messages); n > 0 {
+		last := &ml.Messages[n-1]
+		if last.Text == msg.Text && last.Color == msg.Color && last.Style == msg.Style {
+			last.Dups++
+			last.Index = msg.Index
+			last.Tick = true
+			return
+		}
+	}
+	ml.Messages = append(ml.Messages, msg)
+}
+
+// NewTurn starts a new turn: messages added from now on are marked as new,
+// while the previous ones lose their mark.
+func (ml *MessageLog) NewTurn() {
+	ml.Turn++
+	for i := len(ml.Messages) - 1; i >= 0; i-- {
+		if !ml.Messages[i].Tick {
+			break
+		}
+		ml.Messages[i].Tick = false
+	}
+}
+
+// GetMessages returns up to count of the most recent messages, oldest first.
+func (ml *MessageLog) GetMessages(count int) []Message {
+	start := len(ml.Messages) - count
+	if start < 0 {
+		start = 0
+	}
+	return ml.Messages[start:]
+}
+
+// Search returns the indices of messages whose text contains the given query,
+// ignoring case.
+func (ml *MessageLog) Search(query string) []int {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	var idxs []int
+	for i, m := range ml.Messages {
+		if strings.Contains(strings.ToLower(m.Text), query) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
@@ -0,0 +1,142 @@
+// Package spawntable holds what the monster, item and vault tables have in
+// common: rarity weights, depth ranges, weighted picks by depth, and the
+// overriding of the default templates by the player's data files.
+package spawntable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// Rarity weights: the higher the weight, the more often a template is picked
+// among those allowed at a given depth.
+var rarityWeights = map[string]int{
+	"common":    100,
+	"uncommon":  40,
+	"rare":      15,
+	"very rare": 5,
+}
+
+// Spawn holds the depth range and rarity of a template.
+type Spawn struct {
+	MinDepth int    `json:"min_depth"` // shallowest depth the template spawns at
+	MaxDepth int    `json:"max_depth"` // deepest depth, no limit if zero
+	Rarity   string `json:"rarity"`    // common, uncommon, rare or very rare
+}
+
+// Weight returns the spawn weight of the template.
+func (s Spawn) Weight() int {
+	return rarityWeights[s.Rarity]
+}
+
+// AllowedAt reports whether the template may spawn at the given depth.
+func (s Spawn) AllowedAt(depth int) bool {
+	return depth >= s.MinDepth && (s.MaxDepth == 0 || depth <= s.MaxDepth)
+}
+
+// Validate checks that the depth range and rarity have usable values.
+func (s Spawn) Validate() error {
+	switch {
+	case s.MinDepth < 1:
+		return errors.New("min_depth must be at least 1")
+	case s.MaxDepth != 0 && s.MaxDepth < s.MinDepth:
+		return errors.New("max_depth is below min_depth")
+	}
+	if _, ok := rarityWeights[s.Rarity]; !ok {
+		return fmt.Errorf("unknown rarity %q", s.Rarity)
+	}
+	return nil
+}
+
+// Template is a template of a spawn table.
+type Template interface {
+	Key() string // name of the template, unique in a table
+	Weight() int
+	AllowedAt(depth int) bool
+}
+
+// Table holds templates, in file order.
+type Table[T Template] struct {
+	Templates []T
+}
+
+// Merge adds the templates of other, replacing those with the same name.
+func (tb *Table[T]) Merge(other Table[T]) {
+	for _, t := range other.Templates {
+		if i, ok := tb.index(t.Key()); ok {
+			tb.Templates[i] = t
+			continue
+		}
+		tb.Templates = append(tb.Templates, t)
+	}
+}
+
+// Override merges the templates of the file at path, read by read, if the
+// file exists: templates with the name of a current one replace it, and others
+// are added.
+func (tb *Table[T]) Override(path string, read func(io.Reader) ([]T, error)) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	templates, err := read(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	tb.Merge(Table[T]{Templates: templates})
+	return nil
+}
+
+func (tb *Table[T]) index(name string) (int, bool) {
+	for i, t := range tb.Templates {
+		if t.Key() == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Get returns the template with the given name.
+func (tb *Table[T]) Get(name string) (T, bool) {
+	i, ok := tb.index(name)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return tb.Templates[i], true
+}
+
+// Pick chooses a template allowed at the given depth, weighted by rarity. It
+// returns false if no template is allowed at that depth.
+func (tb *Table[T]) Pick(rng *rand.Rand, depth int) (T, bool) {
+	var zero T
+	total := 0
+	for _, t := range tb.Templates {
+		if t.AllowedAt(depth) {
+			total += t.Weight()
+		}
+	}
+	if total == 0 {
+		return zero, false
+	}
+
+	n := rng.Intn(total)
+	for _, t := range tb.Templates {
+		if !t.AllowedAt(depth) {
+			continue
+		}
+		if n < t.Weight() {
+			return t, true
+		}
+		n -= t.Weight()
+	}
+	return zero, false
+}
@@ -0,0 +1,154 @@
+package spawntable
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	Name  string
+	Power int
+	Spawn
+}
+
+func (e entry) Key() string {
+	return e.Name
+}
+
+func table(entries ...entry) Table[entry] {
+	return Table[entry]{Templates: entries}
+}
+
+func TestMerge(t *testing.T) {
+	tb := table(
+		entry{Name: "orc", Power: 1, Spawn: Spawn{MinDepth: 1, Rarity: "common"}},
+		entry{Name: "troll", Power: 5, Spawn: Spawn{MinDepth: 3, Rarity: "rare"}},
+	)
+	tb.Merge(table(
+		entry{Name: "troll", Power: 7, Spawn: Spawn{MinDepth: 3, Rarity: "rare"}},
+		entry{Name: "wolf", Power: 2, Spawn: Spawn{MinDepth: 1, Rarity: "uncommon"}},
+	))
+
+	var names []string
+	for _, e := range tb.Templates {
+		names = append(names, e.Name)
+	}
+	if got := strings.Join(names, ","); got != "orc,troll,wolf" {
+		t.Errorf("templates = %s, want orc,troll,wolf", got)
+	}
+	if e, ok := tb.Get("troll"); !ok || e.Power != 7 {
+		t.Errorf("Get(troll) = %+v, %v, want the overriding template", e, ok)
+	}
+	if _, ok := tb.Get("dragon"); ok {
+		t.Error("Get(dragon) found a template")
+	}
+}
+
+func TestOverride(t *testing.T) {
+	read := func(r io.Reader) ([]entry, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return []entry{{Name: strings.TrimSpace(string(b)), Spawn: Spawn{MinDepth: 1, Rarity: "common"}}}, nil
+	}
+	dir := t.TempDir()
+	tb := table(entry{Name: "orc", Spawn: Spawn{MinDepth: 1, Rarity: "common"}})
+	if err := tb.Override(filepath.Join(dir, "missing.txt"), read); err != nil || len(tb.Templates) != 1 {
+		t.Fatalf("missing file: %v, %d templates", err, len(tb.Templates))
+	}
+
+	path := filepath.Join(dir, "user.txt")
+	if err := os.WriteFile(path, []byte("wolf\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.Override(path, read); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tb.Get("wolf"); !ok {
+		t.Error("template of the file not added")
+	}
+}
+
+func TestPick(t *testing.T) {
+	tb := table(
+		entry{Name: "rat", Spawn: Spawn{MinDepth: 1, MaxDepth: 2, Rarity: "common"}},
+		entry{Name: "orc", Spawn: Spawn{MinDepth: 2, Rarity: "uncommon"}},
+		entry{Name: "dragon", Spawn: Spawn{MinDepth: 5, Rarity: "very rare"}},
+	)
+	tests := []struct {
+		depth   int
+		allowed string // names that may be picked
+	}{
+		{1, "rat"},
+		{2, "rat,orc"},
+		{3, "orc"},
+		{5, "orc,dragon"},
+	}
+	for _, tt := range tests {
+		rng := rand.New(rand.NewSource(1))
+		seen := map[string]bool{}
+		for range 200 {
+			e, ok := tb.Pick(rng, tt.depth)
+			if !ok {
+				t.Fatalf("depth %d: nothing picked", tt.depth)
+			}
+			seen[e.Name] = true
+		}
+		for name := range seen {
+			if !strings.Contains(","+tt.allowed+",", ","+name+",") {
+				t.Errorf("depth %d: picked %s, allowed %s", tt.depth, name, tt.allowed)
+			}
+		}
+		if len(seen) != strings.Count(tt.allowed, ",")+1 {
+			t.Errorf("depth %d: picked %v, want all of %s", tt.depth, seen, tt.allowed)
+		}
+	}
+
+	empty := table()
+	if _, ok := empty.Pick(rand.New(rand.NewSource(1)), 1); ok {
+		t.Error("picked a template from an empty table")
+	}
+}
+
+func TestPickIsDeterministic(t *testing.T) {
+	tb := table(
+		entry{Name: "rat", Spawn: Spawn{MinDepth: 1, Rarity: "common"}},
+		entry{Name: "orc", Spawn: Spawn{MinDepth: 1, Rarity: "rare"}},
+	)
+	picks := func() string {
+		rng := rand.New(rand.NewSource(42))
+		var names []string
+		for range 20 {
+			e, _ := tb.Pick(rng, 1)
+			names = append(names, e.Name)
+		}
+		return strings.Join(names, ",")
+	}
+	if a, b := picks(), picks(); a != b {
+		t.Errorf("same seed, different picks:\n%s\n%s", a, b)
+	}
+}
+
+func TestSpawnValidate(t *testing.T) {
+	tests := []struct {
+		spawn Spawn
+		err   string
+	}{
+		{Spawn{MinDepth: 1, Rarity: "common"}, ""},
+		{Spawn{MinDepth: 2, MaxDepth: 4, Rarity: "very rare"}, ""},
+		{Spawn{MinDepth: 0, Rarity: "common"}, "min_depth must be at least 1"},
+		{Spawn{MinDepth: 3, MaxDepth: 2, Rarity: "common"}, "max_depth is below min_depth"},
+		{Spawn{MinDepth: 1, Rarity: "legendary"}, `unknown rarity "legendary"`},
+	}
+	for _, tt := range tests {
+		err := tt.spawn.Validate()
+		if (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("%+v: error %v, want %q", tt.spawn, err, tt.err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package turn
 
 import (
+	"bytes"
 	"container/heap"
+	"encoding/gob"
 	"fmt"
 	"time"
 
@@ -48,6 +50,16 @@ func (tq *TurnQueue) Remove(entityID ecs.EntityID) {
 	heap.Remove(tq.queue, index)
 }
 
+// Time returns the time of the next turn of the given entity, and whether it
+// is in the queue.
+func (tq *TurnQueue) Time(entityID ecs.EntityID) (uint64, bool) {
+	index := tq.queue.FindIndex(entityID)
+	if index == -1 {
+		return 0, false
+	}
+	return (*tq.queue)[index].Time, true
+}
+
 // Next removes and returns the next entity (the one with the smallest time)
 // from the queue. Returns the entry and true if the queue is not empty,
 // otherwise returns a zero TurnEntry and false.
@@ -244,3 +256,42 @@ func (tq *TurnQueue) CleanupDeadEntities(world *ecs.ECS) CleanupMetrics {
 	logrus.Debugf("TurnQueue: Cleanup finished. %s\n", metrics)
 	return metrics
 }
+
+// turnQueueData is the serialized form of a TurnQueue.
+type turnQueueData struct {
+	Entries                []TurnEntry
+	CurrentTime            uint64
+	OperationsSinceCleanup uint32
+	TotalCleanups          uint64
+	TotalEntitiesRemoved   uint64
+}
+
+// GobEncode implements gob.GobEncoder.
+func (tq *TurnQueue) GobEncode() ([]byte, error) {
+	data := turnQueueData{
+		Entries:                *tq.queue,
+		CurrentTime:            tq.CurrentTime,
+		OperationsSinceCleanup: tq.OperationsSinceCleanup,
+		TotalCleanups:          tq.TotalCleanups,
+		TotalEntitiesRemoved:   tq.TotalEntitiesRemoved,
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(data)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (tq *TurnQueue) GobDecode(b []byte) error {
+	var data turnQueueData
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+	h := turnHeap(data.Entries)
+	heap.Init(&h)
+	tq.queue = &h
+	tq.CurrentTime = data.CurrentTime
+	tq.OperationsSinceCleanup = data.OperationsSinceCleanup
+	tq.TotalCleanups = data.TotalCleanups
+	tq.TotalEntitiesRemoved = data.TotalEntitiesRemoved
+	return nil
+}
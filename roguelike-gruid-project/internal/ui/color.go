@@ -37,6 +37,7 @@ var (
 	ColorExploredFloor,
 	ColorVisibleWall,
 	ColorVisibleFloor,
+	ColorStairs,
 
 	// Entity colors
 	ColorPlayer,
@@ -44,6 +45,7 @@ var (
 	ColorSleepingMonster,
 	ColorConfusedMonster,
 	ColorParalyzedMonster,
+	ColorFleeingMonster,
 	ColorItem,
 	ColorSpecialItem,
 
@@ -53,6 +55,8 @@ var (
 	ColorUIText,
 	ColorUITitle,
 	ColorUIHighlight,
+	ColorTargetPath,
+	ColorTargetCursor,
 
 	// Status colors
 	ColorHealthOk,
@@ -85,6 +89,7 @@ func init() {
 	ColorExploredFloor = ColorBackground
 	ColorVisibleWall = ColorForegroundEmph
 	ColorVisibleFloor = ColorForeground
+	ColorStairs = ColorCyan
 
 	// Entity colors
 	ColorPlayer = ColorBlue
@@ -92,6 +97,7 @@ func init() {
 	ColorSleepingMonster = ColorViolet
 	ColorConfusedMonster = ColorGreen
 	ColorParalyzedMonster = ColorCyan
+	ColorFleeingMonster = ColorOrange
 	ColorItem = ColorYellow
 	ColorSpecialItem = ColorMagenta
 
@@ -101,6 +107,8 @@ func init() {
 	ColorUIText = ColorForeground
 	ColorUITitle = ColorForegroundEmph
 	ColorUIHighlight = ColorYellow
+	ColorTargetPath = ColorYellow
+	ColorTargetCursor = ColorCyan
 
 	// Status colors
 	ColorHealthOk = ColorGreen
@@ -111,6 +119,27 @@ func init() {
 	ColorStatusNeutral = ColorYellow
 }
 
+// colorNames maps the color names usable in data files to palette colors.
+var colorNames = map[string]gruid.Color{
+	"default": ColorForeground,
+	"white":   ColorForegroundEmph,
+	"grey":    ColorForegroundSecondary,
+	"yellow":  ColorYellow,
+	"orange":  ColorOrange,
+	"red":     ColorRed,
+	"magenta": ColorMagenta,
+	"violet":  ColorViolet,
+	"blue":    ColorBlue,
+	"cyan":    ColorCyan,
+	"green":   ColorGreen,
+}
+
+// ColorByName returns the palette color with the given name.
+func ColorByName(name string) (gruid.Color, bool) {
+	c, ok := colorNames[name]
+	return c, ok
+}
+
 // Helper function to get a style for a map cell based on explored/visible state
 func GetMapStyle(isWall bool, isVisible bool, isExplored bool) gruid.Style {
 	if !isExplored {
@@ -10,6 +10,8 @@ var (
 	ColorPlayerAttack  gruid.Color // Player attacks monsters
 	ColorEnemyAttack   gruid.Color // Monsters attack player
 	ColorNeutralAttack gruid.Color // Monster attacks another monster
+	ColorMiss          gruid.Color // Attack misses
+	ColorCriticalHit   gruid.Color // Critical hits
 
 	// Status colors
 	ColorDeath    gruid.Color // For death messages
@@ -19,9 +21,11 @@ var (
 
 func init() {
 	// Initialize combat colors
-	ColorPlayerAttack = ColorBlue    // Same as player color
-	ColorEnemyAttack = ColorOrange   // Same as monster color
-	ColorNeutralAttack = ColorYellow // Neutral color for monster-monster
+	ColorPlayerAttack = ColorBlue        // Same as player color
+	ColorEnemyAttack = ColorOrange       // Same as monster color
+	ColorNeutralAttack = ColorYellow     // Neutral color for monster-monster
+	ColorMiss = ColorForegroundSecondary // Misses are dimmed
+	ColorCriticalHit = ColorMagenta      // Critical hits stand out
 
 	ColorDeath = ColorRed                  // Death messages are red
 	ColorCorpse = ColorForegroundSecondary // Corpse messages are corpse color
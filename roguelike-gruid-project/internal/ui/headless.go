@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"context"
+	"sync"
+
+	"codeberg.org/anaseto/gruid"
+)
+
+// Headless implements gruid.Driver without any screen: frames are rendered to
+// an in-memory grid, and input messages are supplied with Send. It is meant for
+// tests and automated runs.
+type Headless struct {
+	mu    sync.Mutex
+	grid  gruid.Grid
+	input chan gruid.Msg
+}
+
+// NewHeadless returns a headless driver rendering to a grid of the given size.
+func NewHeadless(width, height int) *Headless {
+	return &Headless{
+		grid:  gruid.NewGrid(width, height),
+		input: make(chan gruid.Msg, 64),
+	}
+}
+
+// Init implements gruid.Driver.Init.
+func (hd *Headless) Init() error {
+	return nil
+}
+
+// PollMsgs implements gruid.Driver.PollMsgs. It forwards the messages supplied
+// with Send until the context is cancelled.
+func (hd *Headless) PollMsgs(ctx context.Context, msgs chan<- gruid.Msg) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-hd.input:
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// Flush implements gruid.Driver.Flush, drawing the frame changes on the
+// in-memory grid.
+func (hd *Headless) Flush(fr gruid.Frame) {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+
+	if size := hd.grid.Size(); fr.Width > 0 && fr.Height > 0 && (size.X != fr.Width || size.Y != fr.Height) {
+		hd.grid = hd.grid.Resize(fr.Width, fr.Height)
+	}
+	for _, fc := range fr.Cells {
+		hd.grid.Set(fc.P, fc.Cell)
+	}
+}
+
+// Close implements gruid.Driver.Close.
+func (hd *Headless) Close() {}
+
+// Send supplies an input message, as if the user had produced it.
+func (hd *Headless) Send(msg gruid.Msg) {
+	hd.input <- msg
+}
+
+// Cell returns the cell drawn at the given position.
+func (hd *Headless) Cell(p gruid.Point) gruid.Cell {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	return hd.grid.At(p)
+}
+
+// String returns the drawn screen as text, one line per grid row, without
+// styling.
+func (hd *Headless) String() string {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	return hd.grid.String()
+}
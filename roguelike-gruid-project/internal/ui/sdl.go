@@ -9,6 +9,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Backend is the name of the display backend of this build.
+const Backend = "sdl"
+
 var driver gruid.Driver
 
 func init() {
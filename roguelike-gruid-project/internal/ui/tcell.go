@@ -9,6 +9,9 @@ import (
 	tc "github.com/gdamore/tcell/v2"
 )
 
+// Backend is the name of the display backend of this build.
+const Backend = "tcell"
+
 var driver gruid.Driver
 
 func init() {
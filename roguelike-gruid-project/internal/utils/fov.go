@@ -8,7 +8,7 @@ import (
 )
 
 func VisionRange(p gruid.Point, radius int) gruid.Range {
-	drg := gruid.NewRange(0, 0, config.DungeonWidth, config.DungeonHeight)
+	drg := gruid.NewRange(0, 0, config.Config.DungeonWidth, config.Config.DungeonHeight)
 	delta := gruid.Point{X: radius, Y: radius}
 	return drg.Intersect(gruid.Range{Min: p.Sub(delta), Max: p.Add(delta).Shift(1, 1)})
 }
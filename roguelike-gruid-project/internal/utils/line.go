@@ -0,0 +1,46 @@
+package utils
+
+import "codeberg.org/anaseto/gruid"
+
+// Line returns the points of a straight line from one point to another, both
+// included, computed with Bresenham's algorithm.
+func Line(from, to gruid.Point) []gruid.Point {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	e := dx + dy
+
+	points := make([]gruid.Point, 0, max(dx, -dy)+1)
+	p := from
+	for {
+		points = append(points, p)
+		if p == to {
+			return points
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			p.Y += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,356 @@
+// Package vaults defines prefab vaults: hand-designed set pieces, such as
+// shrines or monster lairs, drawn as ASCII maps with a legend mapping their
+// characters to terrain, monsters and items. Like the bestiary, a default
+// library is embedded in the binary, and players can override or extend it
+// with their own text file.
+//
+// A vault file holds a list of vaults, each made of "key: value" lines
+// followed by its map:
+//
+//	vault: Shrine
+//	min_depth: 2
+//	rarity: uncommon
+//	legend: ! = item Healing Potion
+//	map:
+//	##+##
+//	#.!.#
+//	#####
+//
+// The map ends at the first blank line. Outside of maps, lines starting with
+// '#' are comments. Besides the characters defined by the legend, maps use
+// '#' for walls, '.' for floor, '+' for entrances joined to the rest of the
+// level, ' ' for tiles left as they are, 'm' for a random monster and 'i' for
+// a random item.
+package vaults
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"codeberg.org/anaseto/gruid"
+	"github.com/lecoqjacob/ai-go/roguelike-gruid-project/internal/spawntable"
+)
+
+//go:embed vaults.txt
+var defaultData []byte
+
+// Terrain is the terrain of a vault cell.
+type Terrain int
+
+const (
+	Outside  Terrain = iota // not part of the vault, left as it is
+	Wall                    // wall
+	Floor                   // floor
+	Entrance                // floor joined to the rest of the level
+)
+
+// Random is the name of a monster or item picked at random for the depth.
+const Random = "random"
+
+// Cell is a cell of a vault map.
+type Cell struct {
+	Terrain Terrain
+	Monster string // monster name, Random, or empty for none
+	Item    string // item name, Random, or empty for none
+}
+
+// defaultLegend holds the characters every vault map can use.
+var defaultLegend = map[rune]Cell{
+	' ': {Terrain: Outside},
+	'#': {Terrain: Wall},
+	'.': {Terrain: Floor},
+	'+': {Terrain: Entrance},
+	'm': {Terrain: Floor, Monster: Random},
+	'i': {Terrain: Floor, Item: Random},
+}
+
+// Template describes a vault.
+type Template struct {
+	Name  string
+	Size  gruid.Point // width and height of the map
+	Cells []Cell      // map cells, row by row
+	spawntable.Spawn
+}
+
+// At returns the cell at the given position of the map.
+func (t Template) At(p gruid.Point) Cell {
+	return t.Cells[p.Y*t.Size.X+p.X]
+}
+
+// Rotate returns the vault turned a quarter clockwise.
+func (t Template) Rotate() Template {
+	r := t
+	r.Size = gruid.Point{X: t.Size.Y, Y: t.Size.X}
+	r.Cells = make([]Cell, len(t.Cells))
+	for y := range r.Size.Y {
+		for x := range r.Size.X {
+			r.Cells[y*r.Size.X+x] = t.At(gruid.Point{X: y, Y: t.Size.Y - 1 - x})
+		}
+	}
+	return r
+}
+
+// Mirror returns the vault flipped from left to right.
+func (t Template) Mirror() Template {
+	r := t
+	r.Cells = make([]Cell, len(t.Cells))
+	for y := range t.Size.Y {
+		for x := range t.Size.X {
+			r.Cells[y*r.Size.X+x] = t.At(gruid.Point{X: t.Size.X - 1 - x, Y: y})
+		}
+	}
+	return r
+}
+
+// Key returns the name of the vault.
+func (t Template) Key() string {
+	return t.Name
+}
+
+// validate checks that the template fields have usable values.
+func (t Template) validate() error {
+	switch {
+	case t.Name == "":
+		return errors.New("missing name")
+	case len(t.Cells) == 0:
+		return fmt.Errorf("%s: missing map", t.Name)
+	}
+	if err := t.Spawn.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", t.Name, err)
+	}
+
+	// Entrances must lead out of the vault
+	entrances := 0
+	for y := range t.Size.Y {
+		for x := range t.Size.X {
+			p := gruid.Point{X: x, Y: y}
+			if t.At(p).Terrain != Entrance {
+				continue
+			}
+			entrances++
+			if !t.onEdge(p) {
+				return fmt.Errorf("%s: entrance at %d,%d is not on the edge of the vault", t.Name, x, y)
+			}
+		}
+	}
+	if entrances == 0 {
+		return fmt.Errorf("%s: no entrance", t.Name)
+	}
+	return nil
+}
+
+// onEdge reports whether the position is next to the outside of the vault.
+func (t Template) onEdge(p gruid.Point) bool {
+	for _, d := range []gruid.Point{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+		q := p.Add(d)
+		if !q.In(gruid.NewRange(0, 0, t.Size.X, t.Size.Y)) || t.At(q).Terrain == Outside {
+			return true
+		}
+	}
+	return false
+}
+
+// Library holds the vault templates, in file order.
+type Library struct {
+	spawntable.Table[Template]
+}
+
+// parser reads vaults from a text file.
+type parser struct {
+	templates []Template
+	t         *Template     // vault being read
+	legend    map[rune]Cell // legend of the vault being read
+	rows      []string      // map of the vault being read
+	inMap     bool
+}
+
+// Read reads a list of vaults in text format.
+func Read(r io.Reader) (*Library, error) {
+	templates, err := readTemplates(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Library{spawntable.Table[Template]{Templates: templates}}, nil
+}
+
+func readTemplates(r io.Reader) ([]Template, error) {
+	var p parser
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if err := p.line(strings.TrimRight(sc.Text(), "\r")); err != nil {
+			return nil, fmt.Errorf("decode vaults: line %d: %w", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("decode vaults: %w", err)
+	}
+	if err := p.end(); err != nil {
+		return nil, fmt.Errorf("decode vaults: %w", err)
+	}
+	for _, t := range p.templates {
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("invalid vault: %w", err)
+		}
+	}
+	return p.templates, nil
+}
+
+// line reads a line of a vault file.
+func (p *parser) line(s string) error {
+	if p.inMap {
+		if strings.TrimSpace(s) == "" {
+			p.inMap = false
+			return nil
+		}
+		p.rows = append(p.rows, s)
+		return nil
+	}
+	if strings.TrimSpace(s) == "" || strings.HasPrefix(s, "#") {
+		return nil
+	}
+
+	key, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return fmt.Errorf("expected \"key: value\", got %q", s)
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if key == "vault" {
+		if err := p.end(); err != nil {
+			return err
+		}
+		p.t = &Template{Name: value, Spawn: spawntable.Spawn{MinDepth: 1, Rarity: "common"}}
+		p.legend = make(map[rune]Cell)
+		return nil
+	}
+	if p.t == nil {
+		return fmt.Errorf("%s before the first vault", key)
+	}
+
+	var err error
+	switch key {
+	case "min_depth":
+		p.t.MinDepth, err = strconv.Atoi(value)
+	case "max_depth":
+		p.t.MaxDepth, err = strconv.Atoi(value)
+	case "rarity":
+		p.t.Rarity = value
+	case "legend":
+		err = p.legendEntry(value)
+	case "map":
+		if len(p.rows) > 0 {
+			return fmt.Errorf("%s: map given twice", p.t.Name)
+		}
+		p.inMap = true
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// legendEntry reads a legend entry of the form "c = kind [name]", where kind
+// is wall, floor, entrance, monster or item.
+func (p *parser) legendEntry(s string) error {
+	char, def, ok := strings.Cut(s, "=")
+	char = strings.TrimSpace(char)
+	if !ok || utf8.RuneCountInString(char) != 1 {
+		return fmt.Errorf("expected \"c = kind [name]\", got %q", s)
+	}
+	c, _ := utf8.DecodeRuneInString(char)
+	if _, ok := defaultLegend[c]; ok {
+		return fmt.Errorf("%q is a built-in character", c)
+	}
+	if _, ok := p.legend[c]; ok {
+		return fmt.Errorf("%q defined twice", c)
+	}
+
+	kind, name, _ := strings.Cut(strings.TrimSpace(def), " ")
+	name = strings.TrimSpace(name)
+	var cell Cell
+	switch kind {
+	case "wall":
+		cell.Terrain = Wall
+	case "floor":
+		cell.Terrain = Floor
+	case "entrance":
+		cell.Terrain = Entrance
+	case "monster":
+		cell = Cell{Terrain: Floor, Monster: name}
+	case "item":
+		cell = Cell{Terrain: Floor, Item: name}
+	default:
+		return fmt.Errorf("unknown kind %q", kind)
+	}
+	switch named := kind == "monster" || kind == "item"; {
+	case named && name == "":
+		return fmt.Errorf("%q: missing %s name", c, kind)
+	case !named && name != "":
+		return fmt.Errorf("%q: %s takes no name", c, kind)
+	}
+	p.legend[c] = cell
+	return nil
+}
+
+// end finishes the vault being read, if any, building its map. Short rows
+// are padded with tiles outside the vault.
+func (p *parser) end() error {
+	p.inMap = false
+	if p.t == nil {
+		return nil
+	}
+	t := p.t
+	for _, row := range p.rows {
+		t.Size.X = max(t.Size.X, utf8.RuneCountInString(row))
+	}
+	t.Size.Y = len(p.rows)
+	t.Cells = make([]Cell, 0, t.Size.X*t.Size.Y)
+	for y, row := range p.rows {
+		n := 0
+		for _, c := range row {
+			cell, ok := p.legend[c]
+			if !ok {
+				cell, ok = defaultLegend[c]
+			}
+			if !ok {
+				return fmt.Errorf("%s: unknown character %q in map row %d", t.Name, c, y+1)
+			}
+			t.Cells = append(t.Cells, cell)
+			n++
+		}
+		for ; n < t.Size.X; n++ {
+			t.Cells = append(t.Cells, Cell{Terrain: Outside})
+		}
+	}
+	p.templates = append(p.templates, *t)
+	p.t, p.legend, p.rows = nil, nil, nil
+	return nil
+}
+
+// Default returns the library embedded in the binary.
+func Default() *Library {
+	l, err := Read(bytes.NewReader(defaultData))
+	if err != nil {
+		panic(fmt.Sprintf("embedded vaults: %v", err))
+	}
+	return l
+}
+
+// Load returns the default library overridden by the file at path, if it
+// exists: vaults with the name of a default one replace it, and others are
+// added.
+func Load(path string) (*Library, error) {
+	l := Default()
+	if err := l.Override(path, readTemplates); err != nil {
+		return nil, err
+	}
+	return l, nil
+}